@@ -0,0 +1,189 @@
+package hw05parallelexecution
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrCycleDetected = errors.New("dependency cycle detected")
+	ErrUnknownNode   = errors.New("unknown node")
+)
+
+// Edge means that task To can be started only after task From has succeeded.
+type Edge struct {
+	From string
+	To   string
+}
+
+type taskResult struct {
+	name string
+	err  error
+}
+
+// RunGraph starts tasks from nodes in n goroutines respecting dependencies from edges
+// and stops its work when receiving m errors from tasks, as Run does.
+// Dependents of a failed task (direct and transitive) are skipped.
+// Cycles and edges to unknown nodes are reported before any task is started.
+func RunGraph(nodes map[string]Task, edges []Edge, n, m int) error {
+	if n <= 0 {
+		return ErrInvalidWorkersCount
+	}
+
+	dependents, inDegree, err := buildGraph(nodes, edges)
+	if err != nil {
+		return err
+	}
+
+	queue := make([]string, 0, len(nodes))
+	for name, degree := range inDegree {
+		if degree == 0 {
+			queue = append(queue, name)
+		}
+	}
+	sort.Strings(queue)
+
+	taskCh := make(chan string)
+	resultCh := make(chan taskResult)
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for name := range taskCh {
+				resultCh <- taskResult{name: name, err: nodes[name]()}
+			}
+		}()
+	}
+
+	var errCount, inFlight int
+	limitExceeded := func() bool {
+		return m > 0 && errCount >= m
+	}
+
+	for {
+		var sendCh chan string
+		var next string
+		if len(queue) > 0 && !limitExceeded() {
+			sendCh = taskCh
+			next = queue[0]
+		}
+
+		if sendCh == nil && inFlight == 0 {
+			break
+		}
+
+		select {
+		case sendCh <- next:
+			queue = queue[1:]
+			inFlight++
+		case res := <-resultCh:
+			inFlight--
+
+			if res.err != nil {
+				errCount++
+				continue
+			}
+
+			for _, dependent := range dependents[res.name] {
+				inDegree[dependent]--
+				if inDegree[dependent] == 0 {
+					queue = append(queue, dependent)
+				}
+			}
+		}
+	}
+
+	close(taskCh)
+	wg.Wait()
+
+	if limitExceeded() {
+		return ErrErrorsLimitExceeded
+	}
+
+	return nil
+}
+
+func buildGraph(nodes map[string]Task, edges []Edge) (map[string][]string, map[string]int, error) {
+	dependents := make(map[string][]string, len(nodes))
+	inDegree := make(map[string]int, len(nodes))
+	for name := range nodes {
+		inDegree[name] = 0
+	}
+
+	for _, edge := range edges {
+		for _, name := range []string{edge.From, edge.To} {
+			if _, ok := nodes[name]; !ok {
+				return nil, nil, fmt.Errorf("%w: %q", ErrUnknownNode, name)
+			}
+		}
+
+		dependents[edge.From] = append(dependents[edge.From], edge.To)
+		inDegree[edge.To]++
+	}
+
+	if cycle := findCycle(nodes, dependents); len(cycle) > 0 {
+		return nil, nil, fmt.Errorf("%w: %s", ErrCycleDetected, strings.Join(cycle, " -> "))
+	}
+
+	return dependents, inDegree, nil
+}
+
+// findCycle returns nodes of the first found cycle, the first node is repeated at the end.
+func findCycle(nodes map[string]Task, dependents map[string][]string) []string {
+	const (
+		unvisited = iota
+		inProgress
+		visited
+	)
+
+	names := make([]string, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	state := make(map[string]int, len(nodes))
+	path := make([]string, 0, len(nodes))
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		state[name] = inProgress
+		path = append(path, name)
+
+		for _, dependent := range dependents[name] {
+			switch state[dependent] {
+			case inProgress:
+				for i, p := range path {
+					if p == dependent {
+						return append(append([]string{}, path[i:]...), dependent)
+					}
+				}
+			case unvisited:
+				if cycle := visit(dependent); cycle != nil {
+					return cycle
+				}
+			}
+		}
+
+		state[name] = visited
+		path = path[:len(path)-1]
+
+		return nil
+	}
+
+	for _, name := range names {
+		if state[name] == unvisited {
+			if cycle := visit(name); cycle != nil {
+				return cycle
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package hw05parallelexecution
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+type execLog struct {
+	mu    sync.Mutex
+	order []string
+}
+
+func (l *execLog) task(name string, err error) Task {
+	return func() error {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		l.order = append(l.order, name)
+		return err
+	}
+}
+
+func (l *execLog) index(name string) int {
+	for i, n := range l.order {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestRunGraph(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("dependencies are respected", func(t *testing.T) {
+		log := &execLog{}
+		nodes := map[string]Task{
+			"fetch":   log.task("fetch", nil),
+			"parse":   log.task("parse", nil),
+			"config":  log.task("config", nil),
+			"build":   log.task("build", nil),
+			"publish": log.task("publish", nil),
+		}
+		edges := []Edge{
+			{From: "fetch", To: "parse"},
+			{From: "parse", To: "build"},
+			{From: "config", To: "build"},
+			{From: "build", To: "publish"},
+		}
+
+		err := RunGraph(nodes, edges, 3, 1)
+		require.NoError(t, err)
+		require.Len(t, log.order, len(nodes))
+
+		for _, edge := range edges {
+			require.Less(t, log.index(edge.From), log.index(edge.To), "%s started before %s", edge.To, edge.From)
+		}
+	})
+
+	t.Run("dependents of failed task are skipped", func(t *testing.T) {
+		log := &execLog{}
+		nodes := map[string]Task{
+			"a": log.task("a", errors.New("a failed")),
+			"b": log.task("b", nil),
+			"c": log.task("c", nil),
+			"d": log.task("d", nil),
+		}
+		edges := []Edge{
+			{From: "a", To: "b"},
+			{From: "b", To: "c"},
+		}
+
+		err := RunGraph(nodes, edges, 2, 0)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"a", "d"}, log.order)
+	})
+
+	t.Run("errors limit exceeded", func(t *testing.T) {
+		var runTasksCount int32
+		nodes := make(map[string]Task)
+		for i := 0; i < 50; i++ {
+			err := fmt.Errorf("error from task %d", i)
+			nodes[fmt.Sprintf("task%02d", i)] = func() error {
+				atomic.AddInt32(&runTasksCount, 1)
+				return err
+			}
+		}
+
+		workersCount := 5
+		maxErrorsCount := 10
+		err := RunGraph(nodes, nil, workersCount, maxErrorsCount)
+		require.ErrorIs(t, err, ErrErrorsLimitExceeded)
+		require.LessOrEqual(t, runTasksCount, int32(workersCount+maxErrorsCount), "extra tasks were started")
+	})
+
+	t.Run("tasks are run concurrently", func(t *testing.T) {
+		workersCount := 3
+		var running, maxRunning int32
+		release := make(chan struct{})
+
+		nodes := make(map[string]Task)
+		for i := 0; i < workersCount; i++ {
+			nodes[fmt.Sprintf("task%d", i)] = func() error {
+				cur := atomic.AddInt32(&running, 1)
+				for {
+					prev := atomic.LoadInt32(&maxRunning)
+					if cur <= prev || atomic.CompareAndSwapInt32(&maxRunning, prev, cur) {
+						break
+					}
+				}
+				<-release
+				atomic.AddInt32(&running, -1)
+				return nil
+			}
+		}
+
+		errCh := make(chan error)
+		go func() {
+			errCh <- RunGraph(nodes, nil, workersCount, 1)
+		}()
+
+		require.Eventually(t, func() bool {
+			return atomic.LoadInt32(&running) == int32(workersCount)
+		}, time.Second, time.Millisecond)
+		close(release)
+
+		require.NoError(t, <-errCh)
+		require.Equal(t, int32(workersCount), atomic.LoadInt32(&maxRunning))
+	})
+
+	t.Run("cycle is detected before run", func(t *testing.T) {
+		var runTasksCount int32
+		task := func() error {
+			atomic.AddInt32(&runTasksCount, 1)
+			return nil
+		}
+		nodes := map[string]Task{"a": task, "b": task, "c": task, "d": task}
+		edges := []Edge{
+			{From: "d", To: "a"},
+			{From: "a", To: "b"},
+			{From: "b", To: "c"},
+			{From: "c", To: "a"},
+		}
+
+		err := RunGraph(nodes, edges, 2, 1)
+		require.ErrorIs(t, err, ErrCycleDetected)
+		require.Contains(t, err.Error(), "a -> b -> c -> a")
+		require.Zero(t, runTasksCount)
+	})
+
+	t.Run("unknown node", func(t *testing.T) {
+		nodes := map[string]Task{"a": func() error { return nil }}
+
+		err := RunGraph(nodes, []Edge{{From: "a", To: "b"}}, 1, 1)
+		require.ErrorIs(t, err, ErrUnknownNode)
+	})
+}
@@ -2,14 +2,58 @@ package hw05parallelexecution
 
 import (
 	"errors"
+	"sync"
+	"sync/atomic"
 )
 
-var ErrErrorsLimitExceeded = errors.New("errors limit exceeded")
+var (
+	ErrErrorsLimitExceeded = errors.New("errors limit exceeded")
+	ErrInvalidWorkersCount = errors.New("workers count must be positive")
+)
 
 type Task func() error
 
 // Run starts tasks in n goroutines and stops its work when receiving m errors from tasks.
+// If m <= 0, errors are ignored and all tasks are executed.
 func Run(tasks []Task, n, m int) error {
-	// Place your code here.
+	if n <= 0 {
+		return ErrInvalidWorkersCount
+	}
+
+	var errCount int32
+	limitExceeded := func() bool {
+		return m > 0 && atomic.LoadInt32(&errCount) >= int32(m)
+	}
+
+	taskCh := make(chan Task)
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for task := range taskCh {
+				if err := task(); err != nil {
+					atomic.AddInt32(&errCount, 1)
+				}
+			}
+		}()
+	}
+
+	for _, task := range tasks {
+		if limitExceeded() {
+			break
+		}
+		taskCh <- task
+	}
+
+	close(taskCh)
+	wg.Wait()
+
+	if limitExceeded() {
+		return ErrErrorsLimitExceeded
+	}
+
 	return nil
 }
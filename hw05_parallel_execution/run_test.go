@@ -67,4 +67,31 @@ func TestRun(t *testing.T) {
 		require.Equal(t, runTasksCount, int32(tasksCount), "not all tasks were completed")
 		require.LessOrEqual(t, int64(elapsedTime), int64(sumTime/2), "tasks were run sequentially?")
 	})
+	t.Run("errors are ignored when m <= 0", func(t *testing.T) {
+		tasksCount := 20
+		tasks := make([]Task, 0, tasksCount)
+
+		var runTasksCount int32
+
+		for i := 0; i < tasksCount; i++ {
+			err := fmt.Errorf("error from task %d", i)
+			tasks = append(tasks, func() error {
+				atomic.AddInt32(&runTasksCount, 1)
+				return err
+			})
+		}
+
+		for _, maxErrorsCount := range []int{0, -1} {
+			atomic.StoreInt32(&runTasksCount, 0)
+
+			err := Run(tasks, 4, maxErrorsCount)
+			require.NoError(t, err)
+			require.Equal(t, int32(tasksCount), atomic.LoadInt32(&runTasksCount))
+		}
+	})
+
+	t.Run("invalid workers count", func(t *testing.T) {
+		err := Run([]Task{func() error { return nil }}, 0, 1)
+		require.ErrorIs(t, err, ErrInvalidWorkersCount)
+	})
 }
@@ -0,0 +1,202 @@
+package hw06pipelineexecution
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrorPolicy defines how Pipeline reacts to stage errors.
+type ErrorPolicy int
+
+const (
+	// FailFast stops reading the input on the first error. Values which passed the failed stage
+	// before the error are still delivered, later values are dropped.
+	FailFast ErrorPolicy = iota
+	// Skip drops values that failed and continues, errors are only counted in metrics.
+	Skip
+	// Collect drops values that failed, continues and reports all errors at the end.
+	Collect
+)
+
+// StageFunc processes a single value and may fail.
+type StageFunc func(v interface{}) (interface{}, error)
+
+// StageError describes an error returned from the named stage.
+type StageError struct {
+	Stage string
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("stage %q: %v", e.Stage, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+type fallibleStage struct {
+	name     string
+	fn       StageFunc
+	counters stageCounters
+}
+
+// Pipeline runs fallible stages on top of ExecutePipeline.
+// A Pipeline must not be executed concurrently.
+type Pipeline struct {
+	policy ErrorPolicy
+	stages []*fallibleStage
+
+	mu     sync.Mutex
+	errs   []error
+	failed int32 // index of the stage failed with FailFast policy, -1 if none
+	halt   Bi    // closed on the failure to stop reading the input
+
+	stop     Bi
+	stopOnce *sync.Once
+}
+
+func NewPipeline(policy ErrorPolicy) *Pipeline {
+	return &Pipeline{policy: policy}
+}
+
+// Stage appends a named stage to the pipeline.
+func (p *Pipeline) Stage(name string, fn StageFunc) *Pipeline {
+	p.stages = append(p.stages, &fallibleStage{name: name, fn: fn})
+	return p
+}
+
+// Execute runs the pipeline. The output is closed when all values are processed,
+// done is closed or, with FailFast policy, a stage fails. Call Err after that.
+func (p *Pipeline) Execute(in In, done In) Out {
+	p.mu.Lock()
+	p.errs = nil
+	atomic.StoreInt32(&p.failed, -1)
+	p.halt = make(Bi)
+	p.mu.Unlock()
+
+	p.stop = make(Bi)
+	p.stopOnce = &sync.Once{}
+
+	stages := make([]Stage, 0, len(p.stages))
+	for i, s := range p.stages {
+		s.counters.reset()
+		stages = append(stages, p.wrap(int32(i), s))
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		select {
+		case <-done:
+			p.abort()
+		case <-finished:
+		}
+	}()
+
+	out := ExecutePipeline(orDone(p.halt, in), p.stop, stages...)
+	result := make(Bi)
+
+	go func() {
+		defer close(finished)
+		defer close(result)
+
+		for v := range out {
+			select {
+			case <-p.stop:
+			case result <- v:
+			}
+		}
+	}()
+
+	return result
+}
+
+// Err returns the first stage error with FailFast policy, all stage errors joined with Collect policy
+// and nil with Skip policy. It must be called after the output channel is closed.
+func (p *Pipeline) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch p.policy {
+	case FailFast:
+		if len(p.errs) > 0 {
+			return p.errs[0]
+		}
+	case Collect:
+		return errors.Join(p.errs...)
+	case Skip:
+	}
+
+	return nil
+}
+
+// Metrics returns counters of every stage in the order of stages.
+func (p *Pipeline) Metrics() []StageMetrics {
+	metrics := make([]StageMetrics, 0, len(p.stages))
+	for _, s := range p.stages {
+		metrics = append(metrics, s.counters.snapshot(s.name))
+	}
+
+	return metrics
+}
+
+func (p *Pipeline) abort() {
+	p.stopOnce.Do(func() { close(p.stop) })
+}
+
+// fail records the error of the stage with index stage.
+func (p *Pipeline) fail(stage int32, err error) {
+	if p.policy == Skip {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.policy == FailFast {
+		if atomic.LoadInt32(&p.failed) >= 0 {
+			return
+		}
+		atomic.StoreInt32(&p.failed, stage)
+		close(p.halt)
+	}
+
+	p.errs = append(p.errs, err)
+}
+
+func (p *Pipeline) wrap(i int32, s *fallibleStage) Stage {
+	return func(in In) Out {
+		out := make(Bi)
+
+		go func() {
+			defer close(out)
+
+			for v := range in {
+				// values reaching the failed stage or stages before it came after the failed value
+				if failed := atomic.LoadInt32(&p.failed); failed >= 0 && i <= failed {
+					continue
+				}
+
+				atomic.AddInt64(&s.counters.in, 1)
+
+				start := time.Now()
+				res, err := s.fn(v)
+				atomic.AddInt64(&s.counters.latency, int64(time.Since(start)))
+
+				if err != nil {
+					atomic.AddInt64(&s.counters.errors, 1)
+					p.fail(i, &StageError{Stage: s.name, Err: err})
+					continue
+				}
+
+				out <- res
+				atomic.AddInt64(&s.counters.out, 1)
+			}
+		}()
+
+		return out
+	}
+}
@@ -0,0 +1,161 @@
+package hw06pipelineexecution
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+var errOdd = errors.New("odd value")
+
+func generateValues(data ...interface{}) In {
+	in := make(Bi)
+	go func() {
+		defer close(in)
+		for _, v := range data {
+			in <- v
+		}
+	}()
+	return in
+}
+
+func newTestPipeline(policy ErrorPolicy) *Pipeline {
+	return NewPipeline(policy).
+		Stage("Multiplier (* 2)", func(v interface{}) (interface{}, error) {
+			return v.(int) * 2, nil
+		}).
+		Stage("Even filter", func(v interface{}) (interface{}, error) {
+			if v.(int)%4 != 0 {
+				return nil, fmt.Errorf("%w: %d", errOdd, v.(int)/2)
+			}
+			return v, nil
+		}).
+		Stage("Stringifier", func(v interface{}) (interface{}, error) {
+			return strconv.Itoa(v.(int)), nil
+		})
+}
+
+func TestPipelineErrors(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("no errors", func(t *testing.T) {
+		p := newTestPipeline(FailFast)
+
+		result := collect(p.Execute(generateValues(2, 4, 6), nil))
+
+		require.Equal(t, []interface{}{"4", "8", "12"}, result)
+		require.NoError(t, p.Err())
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		p := newTestPipeline(FailFast)
+
+		result := collect(p.Execute(generateValues(2, 4, 5, 6, 8), nil))
+
+		// values which passed the filter before the failure are delivered, later ones are dropped
+		require.Equal(t, []interface{}{"4", "8"}, result)
+
+		var stageErr *StageError
+		require.ErrorAs(t, p.Err(), &stageErr)
+		require.Equal(t, "Even filter", stageErr.Stage)
+		require.ErrorIs(t, p.Err(), errOdd)
+		require.EqualError(t, p.Err(), `stage "Even filter": odd value: 5`)
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		p := newTestPipeline(Skip)
+
+		result := collect(p.Execute(generateValues(1, 2, 3, 4), nil))
+
+		require.Equal(t, []interface{}{"4", "8"}, result)
+		require.NoError(t, p.Err())
+		require.Equal(t, int64(2), p.Metrics()[1].Errors)
+	})
+
+	t.Run("collect", func(t *testing.T) {
+		p := newTestPipeline(Collect)
+
+		result := collect(p.Execute(generateValues(1, 2, 3, 4), nil))
+
+		require.Equal(t, []interface{}{"4", "8"}, result)
+		require.ErrorIs(t, p.Err(), errOdd)
+		require.EqualError(t, p.Err(), "stage \"Even filter\": odd value: 1\nstage \"Even filter\": odd value: 3")
+	})
+
+	t.Run("done case", func(t *testing.T) {
+		p := NewPipeline(FailFast).Stage("Sleeper", func(v interface{}) (interface{}, error) {
+			time.Sleep(sleepPerStage)
+			return v, nil
+		})
+		done := make(Bi)
+
+		abortDur := sleepPerStage / 2
+		go func() {
+			<-time.After(abortDur)
+			close(done)
+		}()
+
+		start := time.Now()
+		result := collect(p.Execute(generateValues(1, 2, 3), done))
+		elapsed := time.Since(start)
+
+		require.Len(t, result, 0)
+		require.Less(t, int64(elapsed), int64(abortDur)+int64(fault))
+		require.NoError(t, p.Err())
+	})
+}
+
+func TestPipelineMetrics(t *testing.T) {
+	p := newTestPipeline(Skip)
+
+	collect(p.Execute(generateValues(1, 2, 3, 4, 5, 6), nil))
+	metrics := p.Metrics()
+
+	require.Len(t, metrics, 3)
+	require.Equal(t, "Multiplier (* 2)", metrics[0].Name)
+	require.Equal(t, StageMetrics{Name: "Even filter", In: 6, Out: 3, Errors: 3}, withoutLatency(metrics[1]))
+	require.Equal(t, StageMetrics{Name: "Stringifier", In: 3, Out: 3}, withoutLatency(metrics[2]))
+
+	t.Run("metrics are reset between runs", func(t *testing.T) {
+		collect(p.Execute(generateValues(2), nil))
+
+		require.Equal(t, int64(1), p.Metrics()[0].In)
+	})
+
+	t.Run("latency", func(t *testing.T) {
+		p := NewPipeline(FailFast).Stage("Sleeper", func(v interface{}) (interface{}, error) {
+			time.Sleep(10 * time.Millisecond)
+			return v, nil
+		})
+
+		collect(p.Execute(generateValues(1, 2), nil))
+
+		require.GreaterOrEqual(t, p.Metrics()[0].AvgLatency(), 10*time.Millisecond)
+	})
+}
+
+func withoutLatency(m StageMetrics) StageMetrics {
+	m.Latency = 0
+	return m
+}
+
+func BenchmarkPipeline(b *testing.B) {
+	p := newTestPipeline(Skip)
+	data := make([]interface{}, 0, b.N)
+	for i := 0; i < b.N; i++ {
+		data = append(data, i)
+	}
+
+	b.ResetTimer()
+	collect(p.Execute(generateValues(data...), nil))
+	b.StopTimer()
+
+	for i, m := range p.Metrics() {
+		b.ReportMetric(float64(m.AvgLatency().Nanoseconds()), fmt.Sprintf("ns/value-stage%d", i))
+	}
+}
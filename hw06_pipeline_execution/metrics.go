@@ -0,0 +1,48 @@
+package hw06pipelineexecution
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// StageMetrics is a snapshot of stage counters.
+type StageMetrics struct {
+	Name    string
+	In      int64
+	Out     int64
+	Errors  int64
+	Latency time.Duration // total processing time of all values
+}
+
+// AvgLatency returns average processing time of a single value.
+func (m StageMetrics) AvgLatency() time.Duration {
+	if m.In == 0 {
+		return 0
+	}
+
+	return m.Latency / time.Duration(m.In)
+}
+
+type stageCounters struct {
+	in      int64
+	out     int64
+	errors  int64
+	latency int64
+}
+
+func (c *stageCounters) snapshot(name string) StageMetrics {
+	return StageMetrics{
+		Name:    name,
+		In:      atomic.LoadInt64(&c.in),
+		Out:     atomic.LoadInt64(&c.out),
+		Errors:  atomic.LoadInt64(&c.errors),
+		Latency: time.Duration(atomic.LoadInt64(&c.latency)),
+	}
+}
+
+func (c *stageCounters) reset() {
+	atomic.StoreInt64(&c.in, 0)
+	atomic.StoreInt64(&c.out, 0)
+	atomic.StoreInt64(&c.errors, 0)
+	atomic.StoreInt64(&c.latency, 0)
+}
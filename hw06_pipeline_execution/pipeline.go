@@ -9,6 +9,50 @@ type (
 type Stage func(in In) (out Out)
 
 func ExecutePipeline(in In, done In, stages ...Stage) Out {
-	// Place your code here.
-	return nil
+	out := orDone(done, in)
+	for _, stage := range stages {
+		out = orDone(done, stage(out))
+	}
+
+	return out
+}
+
+// orDone forwards values from in until done is closed.
+// After that in is drained, so the goroutines of previous stages are not blocked forever.
+func orDone[T any](done In, in <-chan T) <-chan T {
+	out := make(chan T)
+
+	go func() {
+		defer func() {
+			close(out)
+			//nolint:revive
+			for range in {
+			}
+		}()
+
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			select {
+			case <-done:
+				return
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+
+				select {
+				case <-done:
+					return
+				case out <- v:
+				}
+			}
+		}
+	}()
+
+	return out
 }
@@ -0,0 +1,195 @@
+package hw06pipelineexecution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrStagePanic = errors.New("stage panicked")
+
+// BufferedStage is a stage of Run. Buffer is a size of the stage output channel,
+// so the stage may process up to Buffer values ahead of a slow consumer.
+type BufferedStage struct {
+	Name   string
+	Fn     StageFunc
+	Buffer int
+}
+
+// Execution is a pipeline started by Run.
+type Execution struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	out    Out
+
+	drain     chan struct{}
+	drainOnce sync.Once
+
+	wg       sync.WaitGroup
+	finished chan struct{}
+
+	mu   sync.Mutex
+	done bool
+	err  error
+}
+
+// Run starts stages one after another, each in its own goroutine.
+// The first stage error or panic aborts the pipeline, as cancellation of ctx does.
+// The output must be read until it is closed or the pipeline is aborted.
+// The input is not drained after the pipeline is aborted or drained.
+// Run panics if a stage has a negative Buffer.
+func Run(ctx context.Context, in In, stages ...BufferedStage) *Execution {
+	for _, s := range stages {
+		if s.Buffer < 0 {
+			panic(fmt.Sprintf("Run: stage %q has negative buffer %d", s.Name, s.Buffer))
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	e := &Execution{
+		ctx:      ctx,
+		cancel:   cancel,
+		drain:    make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+
+	out := e.source(in)
+	for _, s := range stages {
+		out = e.stage(s, out)
+	}
+	e.out = out
+
+	go func() {
+		e.wg.Wait()
+
+		// the result is final once all stages are finished
+		e.mu.Lock()
+		e.done = true
+		e.mu.Unlock()
+		close(e.finished)
+	}()
+
+	return e
+}
+
+// Out returns the output of the last stage.
+func (e *Execution) Out() Out {
+	return e.out
+}
+
+// Drain stops reading the input. Values already taken from the input are processed
+// by all stages, after that the output is closed.
+func (e *Execution) Drain() {
+	e.drainOnce.Do(func() { close(e.drain) })
+}
+
+// Abort stops all stages immediately, values in flight are dropped.
+// Abort of a finished pipeline doesn't change its result.
+func (e *Execution) Abort() {
+	e.fail(context.Canceled)
+	e.cancel()
+}
+
+// Wait waits for all stages to finish and returns the first stage error
+// or the context error if the pipeline was aborted.
+func (e *Execution) Wait() error {
+	<-e.finished
+	e.cancel()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.err
+}
+
+// fail records the first error of a running pipeline.
+func (e *Execution) fail(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.err == nil && !e.done {
+		e.err = err
+	}
+}
+
+func (e *Execution) source(in In) Out {
+	out := make(Bi)
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		defer close(out)
+
+		for {
+			select {
+			case <-e.ctx.Done():
+				e.fail(e.ctx.Err())
+				return
+			case <-e.drain:
+				return
+			case v, ok := <-in:
+				if !ok || !e.send(out, v) {
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+func (e *Execution) stage(s BufferedStage, in In) Out {
+	out := make(Bi, s.Buffer)
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		defer close(out)
+
+		for {
+			select {
+			case <-e.ctx.Done():
+				e.fail(e.ctx.Err())
+				return
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+
+				res, err := call(s, v)
+				if err != nil {
+					e.fail(&StageError{Stage: s.Name, Err: err})
+					e.cancel()
+					return
+				}
+
+				if !e.send(out, res) {
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+func (e *Execution) send(out Bi, v interface{}) bool {
+	select {
+	case <-e.ctx.Done():
+		e.fail(e.ctx.Err())
+		return false
+	case out <- v:
+		return true
+	}
+}
+
+func call(s BufferedStage, v interface{}) (res interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrStagePanic, r)
+		}
+	}()
+
+	return s.Fn(v)
+}
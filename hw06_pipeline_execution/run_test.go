@@ -0,0 +1,164 @@
+package hw06pipelineexecution
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestRun(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	stages := []BufferedStage{
+		{Name: "Multiplier (* 2)", Fn: func(v interface{}) (interface{}, error) { return v.(int) * 2, nil }, Buffer: 2},
+		{Name: "Adder (+ 100)", Fn: func(v interface{}) (interface{}, error) { return v.(int) + 100, nil }},
+		{Name: "Stringifier", Fn: func(v interface{}) (interface{}, error) { return strconv.Itoa(v.(int)), nil }, Buffer: 5},
+	}
+
+	t.Run("simple case", func(t *testing.T) {
+		e := Run(context.Background(), generateValues(1, 2, 3, 4, 5), stages...)
+
+		require.Equal(t, []interface{}{"102", "104", "106", "108", "110"}, collect(e.Out()))
+		require.NoError(t, e.Wait())
+	})
+
+	t.Run("stage error", func(t *testing.T) {
+		errBoom := errors.New("boom")
+		failing := BufferedStage{Name: "Failing", Fn: func(v interface{}) (interface{}, error) {
+			if v.(int) == 3 {
+				return nil, errBoom
+			}
+			return v, nil
+		}}
+
+		// the input is not drained after abort, so it must not block the producer
+		in := make(Bi, 5)
+		for i := 1; i <= 5; i++ {
+			in <- i
+		}
+		close(in)
+
+		e := Run(context.Background(), in, failing)
+		result := collect(e.Out())
+
+		require.NotContains(t, result, 4)
+		require.ErrorIs(t, e.Wait(), errBoom)
+	})
+
+	t.Run("stage panic", func(t *testing.T) {
+		panicking := BufferedStage{Name: "Panicking", Fn: func(v interface{}) (interface{}, error) {
+			return v.(string), nil
+		}}
+
+		e := Run(context.Background(), generateValues(1), panicking)
+
+		require.Empty(t, collect(e.Out()))
+
+		err := e.Wait()
+		require.ErrorIs(t, err, ErrStagePanic)
+
+		var stageErr *StageError
+		require.ErrorAs(t, err, &stageErr)
+		require.Equal(t, "Panicking", stageErr.Stage)
+	})
+
+	t.Run("context cancellation aborts", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		in := make(Bi)
+		defer close(in)
+
+		e := Run(ctx, in, stages...)
+		in <- 1
+		cancel()
+
+		// the output is not read, but all stages must stop anyway
+		require.ErrorIs(t, e.Wait(), context.Canceled)
+	})
+
+	t.Run("abort drops values in flight", func(t *testing.T) {
+		in := make(Bi)
+		defer close(in)
+
+		e := Run(context.Background(), in, stages...)
+		in <- 1
+		in <- 2
+		e.Abort()
+
+		require.ErrorIs(t, e.Wait(), context.Canceled)
+	})
+
+	t.Run("abort after wait", func(t *testing.T) {
+		e := Run(context.Background(), generateValues(1, 2, 3), stages...)
+		require.Len(t, collect(e.Out()), 3)
+
+		require.NoError(t, e.Wait())
+
+		// Abort of the finished pipeline races with another Wait and must not change the result
+		aborted := make(chan struct{})
+		go func() {
+			defer close(aborted)
+			e.Abort()
+		}()
+
+		require.NoError(t, e.Wait())
+		<-aborted
+		require.NoError(t, e.Wait())
+	})
+
+	t.Run("negative buffer", func(t *testing.T) {
+		negative := BufferedStage{Name: "Negative", Fn: stages[0].Fn, Buffer: -1}
+
+		require.PanicsWithValue(t, `Run: stage "Negative" has negative buffer -1`, func() {
+			Run(context.Background(), nil, negative)
+		})
+	})
+
+	t.Run("drain processes accepted values", func(t *testing.T) {
+		in := make(Bi)
+		defer close(in)
+
+		e := Run(context.Background(), in, stages...)
+		in <- 1
+		in <- 2
+		in <- 3
+		e.Drain()
+
+		require.Equal(t, []interface{}{"102", "104", "106"}, collect(e.Out()))
+		require.NoError(t, e.Wait())
+	})
+
+	t.Run("backpressure", func(t *testing.T) {
+		var sent int32
+		in := make(Bi)
+		stop := make(chan struct{})
+		go func() {
+			defer close(in)
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				case in <- i:
+					atomic.AddInt32(&sent, 1)
+				}
+			}
+		}()
+
+		identity := func(v interface{}) (interface{}, error) { return v, nil }
+		e := Run(context.Background(), in, BufferedStage{Name: "Buffered", Fn: identity, Buffer: 3})
+
+		// 3 values in the buffer, one blocked in the stage and one in the source
+		require.Eventually(t, func() bool { return atomic.LoadInt32(&sent) == 5 }, time.Second, time.Millisecond)
+		time.Sleep(10 * time.Millisecond)
+		require.Equal(t, int32(5), atomic.LoadInt32(&sent))
+
+		close(stop)
+		require.GreaterOrEqual(t, len(collect(e.Out())), 5)
+		require.NoError(t, e.Wait())
+	})
+}
@@ -0,0 +1,297 @@
+package hw06pipelineexecution
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Clock abstracts time for time-based stages.
+type Clock interface {
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Stages builds reusable stages. Every stage stops when done is closed,
+// so pass the same done channel that ExecutePipeline receives.
+type Stages struct {
+	done  In
+	clock Clock
+}
+
+func NewStages(done In) *Stages {
+	return NewStagesWithClock(done, realClock{})
+}
+
+func NewStagesWithClock(done In, clock Clock) *Stages {
+	return &Stages{done: done, clock: clock}
+}
+
+// Map applies fn to every value.
+func (s *Stages) Map(fn func(v interface{}) interface{}) Stage {
+	return s.stage(func(v interface{}, emit func(interface{}) bool) bool {
+		return emit(fn(v))
+	})
+}
+
+// Filter passes only values for which keep returns true.
+func (s *Stages) Filter(keep func(v interface{}) bool) Stage {
+	return s.stage(func(v interface{}, emit func(interface{}) bool) bool {
+		return !keep(v) || emit(v)
+	})
+}
+
+// FlatMap emits every value returned by fn one by one.
+func (s *Stages) FlatMap(fn func(v interface{}) []interface{}) Stage {
+	return s.stage(func(v interface{}, emit func(interface{}) bool) bool {
+		for _, res := range fn(v) {
+			if !emit(res) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Dedup drops values whose key has already been seen. Keys must be comparable.
+func (s *Stages) Dedup(keyFn func(v interface{}) interface{}) Stage {
+	return func(in In) Out {
+		seen := make(map[interface{}]struct{})
+
+		return s.stage(func(v interface{}, emit func(interface{}) bool) bool {
+			key := keyFn(v)
+			if _, ok := seen[key]; ok {
+				return true
+			}
+			seen[key] = struct{}{}
+
+			return emit(v)
+		})(in)
+	}
+}
+
+// Tee returns a stage passing values downstream and the side channel receiving a copy of each.
+// The side channel must be read concurrently and is closed when the stage finishes,
+// so the stage can be used once: it panics if applied again.
+func (s *Stages) Tee() (Stage, Out) {
+	side := make(Bi)
+	var used int32
+
+	return func(in In) Out {
+		if !atomic.CompareAndSwapInt32(&used, 0, 1) {
+			panic("Tee: stage is applied more than once")
+		}
+
+		out := make(Bi)
+
+		go func() {
+			defer close(out)
+			defer close(side)
+
+			for {
+				v, ok := s.receive(in)
+				if !ok {
+					return
+				}
+
+				for _, ch := range []Bi{out, side} {
+					if !s.send(ch, v) {
+						return
+					}
+				}
+			}
+		}()
+
+		return out
+	}, side
+}
+
+// Batch groups values into []interface{} of size values.
+// An incomplete batch is emitted when maxWait passes since its first value or the input is closed.
+// It panics if size or maxWait isn't positive.
+func (s *Stages) Batch(size int, maxWait time.Duration) Stage {
+	if size < 1 || maxWait <= 0 {
+		panic(fmt.Sprintf("Batch: size and maxWait must be positive, got %d and %v", size, maxWait))
+	}
+
+	return func(in In) Out {
+		out := make(Bi)
+
+		go func() {
+			defer close(out)
+
+			var batch []interface{}
+			var timeout <-chan time.Time
+
+			flush := func() bool {
+				if len(batch) == 0 {
+					return true
+				}
+
+				ok := s.send(out, batch)
+				batch, timeout = nil, nil
+				return ok
+			}
+
+			for {
+				select {
+				case <-s.done:
+					return
+				case <-timeout:
+					if !flush() {
+						return
+					}
+				case v, ok := <-in:
+					if !ok {
+						flush()
+						return
+					}
+
+					if len(batch) == 0 {
+						batch = make([]interface{}, 0, size)
+						timeout = s.clock.After(maxWait)
+					}
+					batch = append(batch, v)
+
+					if len(batch) >= size && !flush() {
+						return
+					}
+				}
+			}
+		}()
+
+		return out
+	}
+}
+
+// TumblingWindow groups values into []interface{} received during consecutive
+// non-overlapping windows of duration d. Empty windows are not emitted. It panics if d isn't positive.
+func (s *Stages) TumblingWindow(d time.Duration) Stage {
+	if d <= 0 {
+		panic(fmt.Sprintf("TumblingWindow: duration must be positive, got %v", d))
+	}
+
+	return func(in In) Out {
+		out := make(Bi)
+
+		go func() {
+			defer close(out)
+
+			var window []interface{}
+			tick := s.clock.After(d)
+
+			for {
+				select {
+				case <-s.done:
+					return
+				case <-tick:
+					tick = s.clock.After(d)
+					if len(window) > 0 {
+						if !s.send(out, window) {
+							return
+						}
+						window = nil
+					}
+				case v, ok := <-in:
+					if !ok {
+						if len(window) > 0 {
+							s.send(out, window)
+						}
+						return
+					}
+					window = append(window, v)
+				}
+			}
+		}()
+
+		return out
+	}
+}
+
+// Throttle passes no more than rate values per second. It panics if rate isn't positive.
+func (s *Stages) Throttle(rate float64) Stage {
+	if !(rate > 0) { // NaN isn't positive either
+		panic(fmt.Sprintf("Throttle: rate must be positive, got %v", rate))
+	}
+
+	interval := time.Duration(float64(time.Second) / rate)
+
+	return func(in In) Out {
+		out := make(Bi)
+
+		go func() {
+			defer close(out)
+
+			var next <-chan time.Time
+
+			for {
+				v, ok := s.receive(in)
+				if !ok {
+					return
+				}
+
+				if next != nil {
+					select {
+					case <-s.done:
+						return
+					case <-next:
+					}
+				}
+
+				if !s.send(out, v) {
+					return
+				}
+				next = s.clock.After(interval)
+			}
+		}()
+
+		return out
+	}
+}
+
+// stage builds a stage calling process for every value.
+// process returns false when the stage must stop.
+func (s *Stages) stage(process func(v interface{}, emit func(interface{}) bool) bool) Stage {
+	return func(in In) Out {
+		out := make(Bi)
+		emit := func(v interface{}) bool {
+			return s.send(out, v)
+		}
+
+		go func() {
+			defer close(out)
+
+			for {
+				v, ok := s.receive(in)
+				if !ok || !process(v, emit) {
+					return
+				}
+			}
+		}()
+
+		return out
+	}
+}
+
+func (s *Stages) receive(in In) (interface{}, bool) {
+	select {
+	case <-s.done:
+		return nil, false
+	case v, ok := <-in:
+		return v, ok
+	}
+}
+
+func (s *Stages) send(out Bi, v interface{}) bool {
+	select {
+	case <-s.done:
+		return false
+	case out <- v:
+		return true
+	}
+}
@@ -0,0 +1,265 @@
+package hw06pipelineexecution
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+type fakeTimer struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []fakeTimer
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	c.timers = append(c.timers, fakeTimer{deadline: c.now.Add(d), ch: ch})
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.deadline.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.ch <- c.now
+	}
+	c.timers = pending
+}
+
+func (c *fakeClock) waitTimers(t *testing.T, n int) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		return len(c.timers) == n
+	}, time.Second, time.Millisecond)
+}
+
+func receive(t *testing.T, out Out) interface{} {
+	t.Helper()
+
+	select {
+	case v := <-out:
+		return v
+	case <-time.After(time.Second):
+		require.FailNow(t, "no value received")
+		return nil
+	}
+}
+
+func requireNoValue(t *testing.T, out Out) {
+	t.Helper()
+
+	select {
+	case v := <-out:
+		require.FailNow(t, "unexpected value", "%v", v)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestStages(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	s := NewStages(nil)
+
+	t.Run("map, filter, flat map", func(t *testing.T) {
+		stages := []Stage{
+			s.Filter(func(v interface{}) bool { return v.(int)%2 == 0 }),
+			s.Map(func(v interface{}) interface{} { return v.(int) * 10 }),
+			s.FlatMap(func(v interface{}) []interface{} { return []interface{}{v, v.(int) + 1} }),
+		}
+
+		result := collect(ExecutePipeline(generateValues(1, 2, 3, 4), nil, stages...))
+
+		require.Equal(t, []interface{}{20, 21, 40, 41}, result)
+	})
+
+	t.Run("dedup", func(t *testing.T) {
+		stage := s.Dedup(func(v interface{}) interface{} { return v.(string)[0] })
+
+		result := collect(ExecutePipeline(generateValues("apple", "banana", "avocado", "cherry", "blueberry"), nil, stage))
+
+		require.Equal(t, []interface{}{"apple", "banana", "cherry"}, result)
+	})
+
+	t.Run("tee", func(t *testing.T) {
+		tee, side := s.Tee()
+		sideResult := make(chan []interface{})
+		go func() {
+			sideResult <- collect(side)
+		}()
+
+		result := collect(ExecutePipeline(generateValues(1, 2, 3), nil, tee))
+
+		require.Equal(t, []interface{}{1, 2, 3}, result)
+		require.Equal(t, []interface{}{1, 2, 3}, <-sideResult)
+
+		// the side channel is closed, the stage can't be used again
+		require.PanicsWithValue(t, "Tee: stage is applied more than once", func() {
+			tee(generateValues())
+		})
+	})
+
+	t.Run("done", func(t *testing.T) {
+		done := make(Bi)
+		s := NewStages(done)
+		in := make(Bi)
+		defer close(in)
+
+		tee, _ := s.Tee()
+		stages := []Stage{
+			s.Map(func(v interface{}) interface{} { return v }),
+			s.Filter(func(v interface{}) bool { return true }),
+			s.FlatMap(func(v interface{}) []interface{} { return []interface{}{v} }),
+			s.Dedup(func(v interface{}) interface{} { return v }),
+			tee,
+			s.Batch(10, time.Hour),
+			s.TumblingWindow(time.Hour),
+			s.Throttle(1),
+		}
+		out := ExecutePipeline(in, done, stages...)
+		in <- 1
+		close(done)
+
+		require.Empty(t, collect(out))
+	})
+}
+
+func TestTimeStages(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("batch by size", func(t *testing.T) {
+		s := NewStagesWithClock(nil, newFakeClock())
+
+		result := collect(ExecutePipeline(generateValues(1, 2, 3, 4, 5), nil, s.Batch(2, time.Second)))
+
+		require.Equal(t, []interface{}{
+			[]interface{}{1, 2},
+			[]interface{}{3, 4},
+			[]interface{}{5},
+		}, result)
+	})
+
+	t.Run("batch by timeout", func(t *testing.T) {
+		clock := newFakeClock()
+		s := NewStagesWithClock(nil, clock)
+		in := make(Bi)
+		out := s.Batch(3, time.Second)(in)
+
+		in <- 1
+		in <- 2
+		clock.waitTimers(t, 1)
+		clock.Advance(time.Second - time.Millisecond)
+		requireNoValue(t, out)
+
+		clock.Advance(time.Millisecond)
+		require.Equal(t, []interface{}{1, 2}, receive(t, out))
+
+		in <- 3
+		close(in)
+		require.Equal(t, []interface{}{3}, receive(t, out))
+		require.Empty(t, collect(out))
+	})
+
+	t.Run("tumbling window", func(t *testing.T) {
+		clock := newFakeClock()
+		s := NewStagesWithClock(nil, clock)
+		in := make(Bi)
+		out := s.TumblingWindow(time.Minute)(in)
+
+		in <- 1
+		in <- 2
+		clock.waitTimers(t, 1)
+		clock.Advance(time.Minute)
+		require.Equal(t, []interface{}{1, 2}, receive(t, out))
+
+		clock.waitTimers(t, 1)
+		clock.Advance(time.Minute)
+		requireNoValue(t, out)
+
+		in <- 3
+		clock.waitTimers(t, 1)
+		clock.Advance(time.Minute)
+		require.Equal(t, []interface{}{3}, receive(t, out))
+
+		in <- 4
+		close(in)
+		require.Equal(t, []interface{}{4}, receive(t, out))
+		require.Empty(t, collect(out))
+	})
+
+	t.Run("throttle", func(t *testing.T) {
+		clock := newFakeClock()
+		s := NewStagesWithClock(nil, clock)
+		out := s.Throttle(4)(generateValues(1, 2, 3))
+
+		require.Equal(t, 1, receive(t, out))
+		clock.waitTimers(t, 1)
+		requireNoValue(t, out)
+
+		clock.Advance(200 * time.Millisecond)
+		requireNoValue(t, out)
+
+		clock.Advance(50 * time.Millisecond)
+		require.Equal(t, 2, receive(t, out))
+
+		clock.waitTimers(t, 1)
+		clock.Advance(250 * time.Millisecond)
+		require.Equal(t, 3, receive(t, out))
+		require.Empty(t, collect(out))
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		s := NewStages(nil)
+
+		require.PanicsWithValue(t, "Batch: size and maxWait must be positive, got 0 and 1s", func() {
+			s.Batch(0, time.Second)
+		})
+		require.PanicsWithValue(t, "Batch: size and maxWait must be positive, got -1 and 1s", func() {
+			s.Batch(-1, time.Second)
+		})
+		require.PanicsWithValue(t, "Batch: size and maxWait must be positive, got 10 and 0s", func() {
+			s.Batch(10, 0)
+		})
+		require.PanicsWithValue(t, "TumblingWindow: duration must be positive, got -1s", func() {
+			s.TumblingWindow(-time.Second)
+		})
+	})
+
+	t.Run("throttle with invalid rate", func(t *testing.T) {
+		s := NewStages(nil)
+		for _, rate := range []float64{0, -1, math.NaN()} {
+			require.PanicsWithValue(t, fmt.Sprintf("Throttle: rate must be positive, got %v", rate), func() {
+				s.Throttle(rate)
+			})
+		}
+	})
+}
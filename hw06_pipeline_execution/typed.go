@@ -0,0 +1,153 @@
+package hw06pipelineexecution
+
+import (
+	"errors"
+	"sync"
+)
+
+var ErrInvalidWorkersCount = errors.New("workers count must be positive")
+
+// TypedStage is a type-safe counterpart of Stage.
+type TypedStage[I, O any] func(in <-chan I) (out <-chan O)
+
+// Chain composes two stages into one: the output of first becomes the input of second.
+func Chain[A, B, C any](first TypedStage[A, B], second TypedStage[B, C]) TypedStage[A, C] {
+	return func(in <-chan A) <-chan C {
+		return second(first(in))
+	}
+}
+
+// ExecuteTypedPipeline works as ExecutePipeline for a typed stage,
+// usually composed with Chain, Parallel and ParallelOrdered.
+func ExecuteTypedPipeline[I, O any](in <-chan I, done In, stage TypedStage[I, O]) <-chan O {
+	return orDone(done, stage(orDone(done, in)))
+}
+
+// Parallel fans stage out over k goroutines reading the same input.
+// Output values are merged in the order they are produced.
+func Parallel[I, O any](stage TypedStage[I, O], k int) (TypedStage[I, O], error) {
+	if k < 1 {
+		return nil, ErrInvalidWorkersCount
+	}
+
+	return func(in <-chan I) <-chan O {
+		outs := make([]<-chan O, 0, k)
+		for i := 0; i < k; i++ {
+			outs = append(outs, stage(in))
+		}
+
+		return Merge(nil, outs...)
+	}, nil
+}
+
+// ParallelOrdered applies fn to values in k goroutines and keeps the input order of values.
+// Values are distributed round-robin; fn maps every value to exactly one value, so unlike
+// a stage it can't drop values and stall the ordered merge.
+func ParallelOrdered[I, O any](fn func(v I) O, k int) (TypedStage[I, O], error) {
+	if k < 1 {
+		return nil, ErrInvalidWorkersCount
+	}
+
+	return func(in <-chan I) <-chan O {
+		ins := make([]chan I, 0, k)
+		outs := make([]<-chan O, 0, k)
+		for i := 0; i < k; i++ {
+			ch := make(chan I)
+			ins = append(ins, ch)
+			outs = append(outs, mapValues(fn, ch))
+		}
+
+		go func() {
+			defer func() {
+				for _, ch := range ins {
+					close(ch)
+				}
+			}()
+
+			i := 0
+			for v := range in {
+				ins[i] <- v
+				i = (i + 1) % k
+			}
+		}()
+
+		return MergeOrdered(nil, outs...)
+	}, nil
+}
+
+func mapValues[I, O any](fn func(v I) O, in <-chan I) <-chan O {
+	out := make(chan O)
+
+	go func() {
+		defer close(out)
+
+		for v := range in {
+			out <- fn(v)
+		}
+	}()
+
+	return out
+}
+
+// Merge forwards values from all chans to a single channel in the order they arrive.
+// The result is closed when all chans are closed or done is closed.
+func Merge[T any](done In, chans ...<-chan T) <-chan T {
+	out := make(chan T)
+	wg := sync.WaitGroup{}
+
+	for _, ch := range chans {
+		wg.Add(1)
+		go func(ch <-chan T) {
+			defer wg.Done()
+
+			for v := range orDone(done, ch) {
+				select {
+				case <-done:
+				case out <- v:
+				}
+			}
+		}(ch)
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// MergeOrdered takes values from chans in round-robin order.
+// A closed channel is excluded from the rotation; the result is closed
+// when all chans are closed or done is closed.
+func MergeOrdered[T any](done In, chans ...<-chan T) <-chan T {
+	out := make(chan T)
+
+	go func() {
+		defer close(out)
+
+		active := make([]<-chan T, 0, len(chans))
+		for _, ch := range chans {
+			active = append(active, orDone(done, ch))
+		}
+
+		for i := 0; len(active) > 0; {
+			v, ok := <-active[i]
+			if !ok {
+				active = append(active[:i], active[i+1:]...)
+				if len(active) > 0 {
+					i %= len(active)
+				}
+				continue
+			}
+
+			select {
+			case <-done:
+			case out <- v:
+			}
+			i = (i + 1) % len(active)
+		}
+	}()
+
+	return out
+}
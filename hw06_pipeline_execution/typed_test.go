@@ -0,0 +1,151 @@
+package hw06pipelineexecution
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func typedStage[I, O any](f func(v I) O) TypedStage[I, O] {
+	return func(in <-chan I) <-chan O {
+		out := make(chan O)
+		go func() {
+			defer close(out)
+			for v := range in {
+				time.Sleep(sleepPerStage)
+				out <- f(v)
+			}
+		}()
+		return out
+	}
+}
+
+func generate[T any](data ...T) <-chan T {
+	in := make(chan T)
+	go func() {
+		defer close(in)
+		for _, v := range data {
+			in <- v
+		}
+	}()
+	return in
+}
+
+func collect[T any](ch <-chan T) []T {
+	result := make([]T, 0)
+	for v := range ch {
+		result = append(result, v)
+	}
+	return result
+}
+
+func TestTypedPipeline(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	multiplier := typedStage(func(v int) int { return v * 2 })
+	adder := typedStage(func(v int) int { return v + 100 })
+	stringifier := typedStage(strconv.Itoa)
+
+	t.Run("chain", func(t *testing.T) {
+		stage := Chain(Chain(multiplier, adder), stringifier)
+
+		start := time.Now()
+		result := collect(ExecuteTypedPipeline(generate(1, 2, 3, 4, 5), nil, stage))
+		elapsed := time.Since(start)
+
+		require.Equal(t, []string{"102", "104", "106", "108", "110"}, result)
+		require.Less(t, int64(elapsed), int64(sleepPerStage)*int64(3+5-1)+int64(fault))
+	})
+
+	t.Run("parallel", func(t *testing.T) {
+		data := []int{1, 2, 3, 4, 5, 6, 7, 8}
+		stage, err := Parallel(typedStage(strconv.Itoa), len(data))
+		require.NoError(t, err)
+
+		start := time.Now()
+		result := collect(ExecuteTypedPipeline(generate(data...), nil, stage))
+		elapsed := time.Since(start)
+
+		require.ElementsMatch(t, []string{"1", "2", "3", "4", "5", "6", "7", "8"}, result)
+		require.Less(t, int64(elapsed), int64(sleepPerStage)+int64(fault))
+	})
+
+	t.Run("parallel ordered", func(t *testing.T) {
+		data := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+		stage, err := ParallelOrdered(func(v int) string {
+			time.Sleep(sleepPerStage)
+			return strconv.Itoa(v * 2)
+		}, 4)
+		require.NoError(t, err)
+
+		start := time.Now()
+		result := collect(ExecuteTypedPipeline(generate(data...), nil, stage))
+		elapsed := time.Since(start)
+
+		require.Equal(t, []string{"2", "4", "6", "8", "10", "12", "14", "16", "18", "20"}, result)
+		// 3 rounds of 4 workers
+		require.Less(t, int64(elapsed), int64(sleepPerStage)*3+int64(fault))
+	})
+
+	t.Run("invalid number of workers", func(t *testing.T) {
+		for _, k := range []int{0, -1} {
+			_, err := Parallel(stringifier, k)
+			require.ErrorIs(t, err, ErrInvalidWorkersCount)
+
+			_, err = ParallelOrdered(strconv.Itoa, k)
+			require.ErrorIs(t, err, ErrInvalidWorkersCount)
+		}
+	})
+
+	t.Run("done case", func(t *testing.T) {
+		done := make(Bi)
+		parallel, err := Parallel(multiplier, 3)
+		require.NoError(t, err)
+		ordered, err := ParallelOrdered(func(v int) string {
+			time.Sleep(sleepPerStage)
+			return strconv.Itoa(v)
+		}, 3)
+		require.NoError(t, err)
+		stage := Chain(parallel, ordered)
+
+		abortDur := sleepPerStage / 2
+		go func() {
+			<-time.After(abortDur)
+			close(done)
+		}()
+
+		start := time.Now()
+		result := collect(ExecuteTypedPipeline(generate(1, 2, 3, 4, 5, 6), done, stage))
+		elapsed := time.Since(start)
+
+		require.Len(t, result, 0)
+		require.Less(t, int64(elapsed), int64(abortDur)+int64(fault))
+	})
+}
+
+func TestMerge(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("unordered", func(t *testing.T) {
+		result := collect(Merge(nil, generate(1, 2, 3), generate(4, 5), generate[int]()))
+
+		require.ElementsMatch(t, []int{1, 2, 3, 4, 5}, result)
+	})
+
+	t.Run("ordered", func(t *testing.T) {
+		result := collect(MergeOrdered(nil, generate(1, 4, 6), generate(2, 5), generate(3)))
+
+		require.Equal(t, []int{1, 2, 3, 4, 5, 6}, result)
+	})
+
+	t.Run("done", func(t *testing.T) {
+		done := make(Bi)
+		close(done)
+
+		require.Empty(t, collect(Merge(done, generate(1, 2, 3))))
+		require.Empty(t, collect(MergeOrdered(done, generate(1, 2, 3))))
+	})
+}
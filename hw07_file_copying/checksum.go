@@ -0,0 +1,55 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"strings"
+)
+
+var (
+	ErrUnsupportedHash = errors.New("unsupported hash")
+	ErrHashRequired    = errors.New("hash is required to verify checksum")
+)
+
+// ChecksumError is returned when the checksum of the copied range differs from the expected one.
+type ChecksumError struct {
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("checksum mismatch: expected %s, got %s", e.Expected, e.Actual)
+}
+
+// NewHash returns a hash by its name: sha256 or crc32 (IEEE).
+func NewHash(name string) (hash.Hash, error) {
+	switch strings.ToLower(name) {
+	case "sha256":
+		return sha256.New(), nil
+	case "crc32":
+		return crc32.NewIEEE(), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedHash, name)
+	}
+}
+
+// Sum returns hex encoded sum of h.
+func Sum(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func verifyChecksum(h hash.Hash, expected string) error {
+	if h == nil || expected == "" {
+		return nil
+	}
+
+	if actual := Sum(h); !strings.EqualFold(actual, expected) {
+		return &ChecksumError{Expected: expected, Actual: actual}
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyChecksum(t *testing.T) {
+	expected, err := os.ReadFile("testdata/out_offset100_limit1000.txt")
+	require.NoError(t, err)
+
+	sha := sha256.Sum256(expected)
+	expectedSHA := hex.EncodeToString(sha[:])
+	expectedCRC := fmt.Sprintf("%08x", crc32.ChecksumIEEE(expected))
+
+	t.Run("hash of copied range", func(t *testing.T) {
+		for name, sum := range map[string]string{"sha256": expectedSHA, "crc32": expectedCRC, "SHA256": expectedSHA} {
+			h, err := NewHash(name)
+			require.NoError(t, err)
+
+			toPath := filepath.Join(t.TempDir(), "out.txt")
+			require.NoError(t, CopyWithOptions(inputPath, toPath, 100, 1000, Options{Hash: h}))
+
+			require.Equal(t, sum, Sum(h), name)
+			requireFileEqual(t, "testdata/out_offset100_limit1000.txt", toPath)
+		}
+	})
+
+	t.Run("verified", func(t *testing.T) {
+		h, err := NewHash("sha256")
+		require.NoError(t, err)
+
+		toPath := filepath.Join(t.TempDir(), "out.txt")
+		err = CopyWithOptions(inputPath, toPath, 100, 1000, Options{Hash: h, Expected: expectedSHA})
+		require.NoError(t, err)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		h, err := NewHash("crc32")
+		require.NoError(t, err)
+
+		dir := t.TempDir()
+		toPath := filepath.Join(dir, "out.txt")
+		require.NoError(t, os.WriteFile(toPath, []byte("old"), 0o644))
+
+		err = CopyWithOptions(inputPath, toPath, 0, 1000, Options{Hash: h, Expected: expectedCRC})
+
+		var checksumErr *ChecksumError
+		require.ErrorAs(t, err, &checksumErr)
+		require.Equal(t, expectedCRC, checksumErr.Expected)
+		require.NotEqual(t, expectedCRC, checksumErr.Actual)
+
+		actual, err := os.ReadFile(toPath)
+		require.NoError(t, err)
+		require.Equal(t, "old", string(actual))
+		requireDirFiles(t, dir, "out.txt")
+	})
+
+	t.Run("resume hashes the whole range", func(t *testing.T) {
+		toPath := filepath.Join(t.TempDir(), "out.txt")
+		require.NoError(t, Copy(inputPath, toPath, 100, 400))
+
+		h, err := NewHash("sha256")
+		require.NoError(t, err)
+
+		err = CopyWithOptions(inputPath, toPath, 100, 1000, Options{Resume: true, Hash: h, Expected: expectedSHA})
+		require.NoError(t, err)
+		requireFileEqual(t, "testdata/out_offset100_limit1000.txt", toPath)
+	})
+
+	t.Run("resume mismatch keeps destination", func(t *testing.T) {
+		dir := t.TempDir()
+		toPath := filepath.Join(dir, "out.txt")
+		require.NoError(t, Copy(inputPath, toPath, 100, 400))
+		partial, err := os.ReadFile(toPath)
+		require.NoError(t, err)
+
+		h, err := NewHash("crc32")
+		require.NoError(t, err)
+
+		err = CopyWithOptions(inputPath, toPath, 100, 1000, Options{Resume: true, Hash: h, Expected: expectedSHA})
+		var checksumErr *ChecksumError
+		require.ErrorAs(t, err, &checksumErr)
+
+		actual, err := os.ReadFile(toPath)
+		require.NoError(t, err)
+		require.Equal(t, partial, actual)
+		requireDirFiles(t, dir, "out.txt")
+	})
+
+	t.Run("resume mismatch doesn't create destination", func(t *testing.T) {
+		dir := t.TempDir()
+		toPath := filepath.Join(dir, "out.txt")
+
+		h, err := NewHash("crc32")
+		require.NoError(t, err)
+
+		err = CopyWithOptions(inputPath, toPath, 100, 1000, Options{Resume: true, Hash: h, Expected: expectedSHA})
+		var checksumErr *ChecksumError
+		require.ErrorAs(t, err, &checksumErr)
+
+		require.NoFileExists(t, toPath)
+		requireDirFiles(t, dir)
+	})
+
+	t.Run("named pipe", func(t *testing.T) {
+		input, err := os.ReadFile(inputPath)
+		require.NoError(t, err)
+
+		h, err := NewHash("sha256")
+		require.NoError(t, err)
+
+		toPath := filepath.Join(t.TempDir(), "out.txt")
+		err = CopyWithOptions(namedPipe(t, input), toPath, 100, 1000, Options{Hash: h, Expected: expectedSHA})
+		require.NoError(t, err)
+	})
+
+	t.Run("unsupported hash", func(t *testing.T) {
+		_, err := NewHash("md5")
+		require.ErrorIs(t, err, ErrUnsupportedHash)
+	})
+
+	t.Run("expected checksum without hash", func(t *testing.T) {
+		toPath := filepath.Join(t.TempDir(), "out.txt")
+
+		err := CopyWithOptions(inputPath, toPath, 0, 0, Options{Expected: expectedSHA})
+		require.ErrorIs(t, err, ErrHashRequired)
+	})
+}
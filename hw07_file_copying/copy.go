@@ -2,14 +2,368 @@ package main
 
 import (
 	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync/atomic"
 )
 
 var (
 	ErrUnsupportedFile       = errors.New("unsupported file")
 	ErrOffsetExceedsFileSize = errors.New("offset exceeds file size")
+	ErrDestinationTooLarge   = errors.New("destination is larger than the copied range")
+	ErrIsDirectory           = errors.New("is a directory")
+	ErrUnknownSize           = errors.New("size is unknown, limit is required")
+
+	errSourceTruncated = fmt.Errorf("source is shorter than expected: %w", io.ErrUnexpectedEOF)
 )
 
+// ProgressReporter receives the number of copied bytes and the total number of bytes to copy.
+type ProgressReporter interface {
+	Report(copied, total int64)
+}
+
+type Options struct {
+	// Progress is notified after every written chunk, may be nil.
+	Progress ProgressReporter
+	// Resume continues a partial copy: bytes already present in the destination are skipped
+	// and the rest is appended to it in place. With Expected the destination and the rest
+	// are written to a temporary file instead, so the destination is kept on mismatch.
+	Resume bool
+	// Buffered disables the fast path for regular files (zero-copy and holes preserving)
+	// and always copies through a userland buffer.
+	Buffered bool
+	// Hash receives every byte of the copied range, may be nil. The fast path is not used with it.
+	Hash hash.Hash
+	// Expected is a hex encoded checksum to verify Hash against after the copy.
+	// On mismatch the destination is not replaced or modified and *ChecksumError is returned.
+	Expected string
+	// Compress and Decompress transform the copied range of the source, may be nil.
+	// The checksum is computed for the written (transformed) bytes.
+	Compress   *Codec
+	Decompress *Codec
+	// Parallel splits the copied range into that many parts copied concurrently.
+	// Only regular sources without resume, hash and transforms are supported.
+	Parallel int
+}
+
+func (o Options) transforms() bool {
+	return o.Compress != nil || o.Decompress != nil
+}
+
 func Copy(fromPath, toPath string, offset, limit int64) error {
-	// Place your code here.
+	return CopyWithOptions(fromPath, toPath, offset, limit, Options{})
+}
+
+// CopyWithOptions copies limit bytes (the whole rest of file if limit is 0) starting from offset.
+// Unless resuming, the destination is written to a temporary file which replaces toPath
+// only when the copy succeeds.
+func CopyWithOptions(fromPath, toPath string, offset, limit int64, opts Options) error {
+	if opts.Expected != "" && opts.Hash == nil {
+		return ErrHashRequired
+	}
+
+	if opts.Resume && opts.transforms() {
+		return ErrResumeWithTransform
+	}
+
+	if opts.Parallel > 1 && (opts.Resume || opts.Hash != nil || opts.transforms()) {
+		return ErrParallelUnsupported
+	}
+
+	src, err := openSource(fromPath, offset, limit)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if opts.Parallel > 1 && !src.regular {
+		return fmt.Errorf("%w: %s is not a regular file", ErrParallelUnsupported, fromPath)
+	}
+
+	if opts.Resume {
+		return resumeCopy(src, toPath, offset, opts)
+	}
+
+	return atomicWrite(toPath, func(dst *os.File) error {
+		if err := copyRange(src, dst, offset, 0, opts); err != nil {
+			return err
+		}
+		return verifyChecksum(opts.Hash, opts.Expected)
+	})
+}
+
+// source is a file to copy from. Regular files have known size,
+// other files (pipes, character devices) are read sequentially up to limit bytes.
+type source struct {
+	*os.File
+	regular bool
+	total   int64 // number of bytes to copy, the upper bound for non-regular files
+}
+
+func openSource(path string, offset, limit int64) (*source, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := newSource(f, offset, limit)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return src, nil
+}
+
+func newSource(f *os.File, offset, limit int64) (*source, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if offset < 0 {
+		return nil, ErrOffsetExceedsFileSize
+	}
+
+	switch mode := info.Mode(); {
+	case mode.IsDir():
+		return nil, fmt.Errorf("%w: %s: %w", ErrUnsupportedFile, f.Name(), ErrIsDirectory)
+	case mode.IsRegular():
+		if offset > info.Size() {
+			return nil, ErrOffsetExceedsFileSize
+		}
+
+		total := info.Size() - offset
+		if limit > 0 && limit < total {
+			total = limit
+		}
+
+		return &source{File: f, regular: true, total: total}, nil
+	case mode&(os.ModeNamedPipe|os.ModeCharDevice|os.ModeSocket) != 0:
+		if limit <= 0 {
+			return nil, fmt.Errorf("%w: %s: %w", ErrUnsupportedFile, f.Name(), ErrUnknownSize)
+		}
+
+		return &source{File: f, total: limit}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFile, f.Name())
+	}
+}
+
+// skip positions the source at offset from its beginning.
+// Non-regular files can't seek, so the bytes are read and discarded.
+func (s *source) skip(offset int64) error {
+	if s.regular {
+		_, err := s.Seek(offset, io.SeekStart)
+		return err
+	}
+
+	n, err := io.CopyN(io.Discard, s, offset)
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: stream ended after %d bytes", ErrOffsetExceedsFileSize, n)
+	}
+
+	return err
+}
+
+func resumeCopy(src *source, toPath string, offset int64, opts Options) error {
+	if opts.Expected != "" {
+		return verifiedResume(src, toPath, offset, opts)
+	}
+
+	dst, err := os.OpenFile(toPath, os.O_RDWR|os.O_CREATE, 0o666)
+	if err != nil {
+		return err
+	}
+
+	copied, err := resumedSize(dst, src.total)
+	if err != nil {
+		dst.Close()
+		return err
+	}
+
+	if opts.Hash != nil {
+		// the checksum covers the whole range including the already copied part
+		if _, err := io.CopyN(opts.Hash, dst, copied); err != nil {
+			dst.Close()
+			return err
+		}
+	}
+
+	if err := copyRange(src, dst, offset, copied, opts); err != nil {
+		dst.Close()
+		return err
+	}
+
+	return dst.Close()
+}
+
+// verifiedResume continues a partial copy in a temporary file which replaces the destination
+// only if the whole range matches the expected checksum. Appending in place would corrupt
+// the destination on mismatch.
+func verifiedResume(src *source, toPath string, offset int64, opts Options) error {
+	var copied int64
+
+	dst, err := os.Open(toPath)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		// nothing is copied yet, a missing destination is not created on mismatch
+	case err != nil:
+		return err
+	default:
+		defer dst.Close()
+
+		if copied, err = resumedSize(dst, src.total); err != nil {
+			return err
+		}
+	}
+
+	return atomicWrite(toPath, func(tmp *os.File) error {
+		if copied > 0 {
+			if _, err := io.CopyN(io.MultiWriter(tmp, opts.Hash), dst, copied); err != nil {
+				return err
+			}
+		}
+		if err := copyRange(src, tmp, offset, copied, opts); err != nil {
+			return err
+		}
+		return verifyChecksum(opts.Hash, opts.Expected)
+	})
+}
+
+// resumedSize returns the number of bytes already copied to the destination.
+func resumedSize(dst *os.File, total int64) (int64, error) {
+	info, err := dst.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	if info.Size() > total {
+		return 0, fmt.Errorf("%w: %d > %d", ErrDestinationTooLarge, info.Size(), total)
+	}
+
+	return info.Size(), nil
+}
+
+// atomicWrite calls write for a temporary file in the directory of path and renames it to path on success.
+// The permissions of an existing destination are kept, a new one is created like os.Create does.
+func atomicWrite(path string, write func(f *os.File) error) (err error) {
+	perm, existing := os.FileMode(0o666), false
+	if info, statErr := os.Stat(path); statErr == nil {
+		perm, existing = info.Mode().Perm(), true
+	}
+
+	tmp, err := createTemp(path, perm)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if err = write(tmp); err != nil {
+		return err
+	}
+
+	// umask may have cleared some bits of the existing mode
+	if existing {
+		if err = tmp.Chmod(perm); err != nil {
+			return err
+		}
+	}
+
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+var tempSeq uint32
+
+// createTemp creates a new temporary file next to path. Unlike os.CreateTemp it uses perm
+// which is subject to umask, not 0600.
+func createTemp(path string, perm os.FileMode) (*os.File, error) {
+	dir, base := filepath.Split(path)
+	for {
+		name := filepath.Join(dir, fmt.Sprintf(".%s.%d-%d.tmp", base, os.Getpid(), atomic.AddUint32(&tempSeq, 1)))
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, perm)
+		if !errors.Is(err, fs.ErrExist) {
+			return f, err
+		}
+	}
+}
+
+// copyRange copies src.total-copied bytes from src starting at offset+copied
+// to dst starting at copied. A non-regular source may end earlier, this is not an error.
+func copyRange(src *source, dst *os.File, offset, copied int64, opts Options) error {
+	if err := src.skip(offset + copied); err != nil {
+		return err
+	}
+
+	if _, err := dst.Seek(copied, io.SeekStart); err != nil {
+		return err
+	}
+
+	report := func(int64) {}
+	if opts.Progress != nil {
+		report = func(n int64) { opts.Progress.Report(n, src.total) }
+	}
+	report(copied)
+
+	if opts.Parallel > 1 {
+		return parallelCopy(dst, src.File, offset, src.total, opts.Parallel, report)
+	}
+
+	if src.regular && !opts.Buffered && opts.Hash == nil && !opts.transforms() {
+		return fastCopy(dst, src.File, offset+copied, copied, src.total-copied, report)
+	}
+
+	// progress is measured in source bytes, they differ from written bytes with transforms
+	pr := &progressReader{r: io.LimitReader(src, src.total-copied), copied: copied, report: report}
+
+	if err := transformCopy(dst, pr, opts); err != nil {
+		return err
+	}
+
+	// a decompressor may stop before the end of the range, the rest is read to detect truncation
+	if opts.Decompress != nil {
+		if _, err := io.Copy(io.Discard, pr); err != nil {
+			return err
+		}
+	}
+
+	if src.regular && pr.copied < src.total {
+		return errSourceTruncated
+	}
+
 	return nil
 }
+
+type progressReader struct {
+	r      io.Reader
+	copied int64
+	report func(copied int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.copied += int64(n)
+		p.report(p.copied)
+	}
+
+	return n, err
+}
@@ -1,7 +1,318 @@
 package main
 
-import "testing"
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const inputPath = "testdata/input.txt"
+
+type progressRecorder struct {
+	reports [][2]int64
+}
+
+func (r *progressRecorder) Report(copied, total int64) {
+	r.reports = append(r.reports, [2]int64{copied, total})
+}
 
 func TestCopy(t *testing.T) {
-	// Place your code here.
+	tests := []struct {
+		offset, limit int64
+	}{
+		{offset: 0, limit: 0},
+		{offset: 0, limit: 10},
+		{offset: 0, limit: 1000},
+		{offset: 0, limit: 10000},
+		{offset: 100, limit: 1000},
+		{offset: 6000, limit: 1000},
+	}
+
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("offset %d limit %d", tc.offset, tc.limit), func(t *testing.T) {
+			toPath := filepath.Join(t.TempDir(), "out.txt")
+
+			err := Copy(inputPath, toPath, tc.offset, tc.limit)
+			require.NoError(t, err)
+
+			expected, err := os.ReadFile(fmt.Sprintf("testdata/out_offset%d_limit%d.txt", tc.offset, tc.limit))
+			require.NoError(t, err)
+			actual, err := os.ReadFile(toPath)
+			require.NoError(t, err)
+			require.Equal(t, expected, actual)
+		})
+	}
+
+	t.Run("offset exceeds file size", func(t *testing.T) {
+		toPath := filepath.Join(t.TempDir(), "out.txt")
+
+		err := Copy(inputPath, toPath, 7000, 0)
+		require.ErrorIs(t, err, ErrOffsetExceedsFileSize)
+		require.NoFileExists(t, toPath)
+	})
+
+	t.Run("offset equals file size", func(t *testing.T) {
+		toPath := filepath.Join(t.TempDir(), "out.txt")
+
+		err := Copy(inputPath, toPath, 6617, 0)
+		require.NoError(t, err)
+
+		actual, err := os.ReadFile(toPath)
+		require.NoError(t, err)
+		require.Empty(t, actual)
+	})
+
+	t.Run("source does not exist", func(t *testing.T) {
+		toPath := filepath.Join(t.TempDir(), "out.txt")
+
+		err := Copy("testdata/missing.txt", toPath, 0, 0)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestCopyNonRegular(t *testing.T) {
+	input, err := os.ReadFile(inputPath)
+	require.NoError(t, err)
+
+	t.Run("character device with limit", func(t *testing.T) {
+		toPath := filepath.Join(t.TempDir(), "out.txt")
+
+		err := Copy("/dev/urandom", toPath, 10, 1000)
+		require.NoError(t, err)
+
+		info, err := os.Stat(toPath)
+		require.NoError(t, err)
+		require.Equal(t, int64(1000), info.Size())
+	})
+
+	t.Run("character device without limit", func(t *testing.T) {
+		toPath := filepath.Join(t.TempDir(), "out.txt")
+
+		err := Copy("/dev/urandom", toPath, 0, 0)
+		require.ErrorIs(t, err, ErrUnsupportedFile)
+		require.ErrorIs(t, err, ErrUnknownSize)
+		require.NoFileExists(t, toPath)
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		toPath := filepath.Join(t.TempDir(), "out.txt")
+
+		err := Copy("testdata", toPath, 0, 10)
+		require.ErrorIs(t, err, ErrUnsupportedFile)
+		require.ErrorIs(t, err, ErrIsDirectory)
+		require.NoFileExists(t, toPath)
+	})
+
+	tests := []struct {
+		name          string
+		offset, limit int64
+		expectedPath  string
+	}{
+		{name: "named pipe", offset: 100, limit: 1000, expectedPath: "testdata/out_offset100_limit1000.txt"},
+		{
+			name:         "named pipe shorter than limit",
+			offset:       6000,
+			limit:        1000,
+			expectedPath: "testdata/out_offset6000_limit1000.txt",
+		},
+		{name: "named pipe whole data", offset: 0, limit: 10000, expectedPath: "testdata/out_offset0_limit0.txt"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fromPath := namedPipe(t, input)
+			toPath := filepath.Join(t.TempDir(), "out.txt")
+
+			err := Copy(fromPath, toPath, tc.offset, tc.limit)
+			require.NoError(t, err)
+
+			requireFileEqual(t, tc.expectedPath, toPath)
+		})
+	}
+
+	t.Run("named pipe without limit", func(t *testing.T) {
+		fromPath := namedPipe(t, input)
+		toPath := filepath.Join(t.TempDir(), "out.txt")
+
+		err := Copy(fromPath, toPath, 0, 0)
+		require.ErrorIs(t, err, ErrUnknownSize)
+	})
+
+	t.Run("offset beyond end of named pipe", func(t *testing.T) {
+		fromPath := namedPipe(t, input[:10])
+		toPath := filepath.Join(t.TempDir(), "out.txt")
+
+		err := Copy(fromPath, toPath, 100, 10)
+		require.ErrorIs(t, err, ErrOffsetExceedsFileSize)
+		require.NoFileExists(t, toPath)
+	})
+}
+
+// namedPipe creates a FIFO and writes data into it as soon as a reader opens it.
+func namedPipe(t *testing.T, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "fifo")
+	require.NoError(t, syscall.Mkfifo(path, 0o600))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		f, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+
+		f.Write(data)
+	}()
+
+	t.Cleanup(func() {
+		// unblock the writer if the pipe was never opened for reading
+		if f, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NONBLOCK, 0); err == nil {
+			f.Close()
+		}
+		<-done
+	})
+
+	return path
+}
+
+func TestCopyAtomic(t *testing.T) {
+	t.Run("destination is replaced", func(t *testing.T) {
+		dir := t.TempDir()
+		toPath := filepath.Join(dir, "out.txt")
+		require.NoError(t, os.WriteFile(toPath, []byte("old content that is longer than limit"), 0o644))
+
+		err := Copy(inputPath, toPath, 0, 10)
+		require.NoError(t, err)
+
+		requireFileEqual(t, "testdata/out_offset0_limit10.txt", toPath)
+		requireDirFiles(t, dir, "out.txt")
+	})
+
+	t.Run("permissions of destination are kept", func(t *testing.T) {
+		dir := t.TempDir()
+		existing, created := filepath.Join(dir, "existing.txt"), filepath.Join(dir, "created.txt")
+		require.NoError(t, os.WriteFile(existing, []byte("old"), 0o600))
+		require.NoError(t, os.Chmod(existing, 0o666))
+
+		// a new destination is subject to umask, an existing one keeps its mode
+		defer syscall.Umask(syscall.Umask(0o027))
+		require.NoError(t, Copy(inputPath, existing, 0, 10))
+		require.NoError(t, Copy(inputPath, created, 0, 10))
+
+		info, err := os.Stat(existing)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o666), info.Mode().Perm())
+
+		info, err = os.Stat(created)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+	})
+
+	t.Run("destination is kept on failure", func(t *testing.T) {
+		dir := t.TempDir()
+		toPath := filepath.Join(dir, "out.txt")
+		require.NoError(t, os.WriteFile(toPath, []byte("old"), 0o644))
+
+		err := Copy(inputPath, toPath, 10000, 0)
+		require.Error(t, err)
+
+		actual, err := os.ReadFile(toPath)
+		require.NoError(t, err)
+		require.Equal(t, "old", string(actual))
+		requireDirFiles(t, dir, "out.txt")
+	})
+
+	t.Run("destination directory does not exist", func(t *testing.T) {
+		toPath := filepath.Join(t.TempDir(), "missing", "out.txt")
+
+		err := Copy(inputPath, toPath, 0, 0)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestCopyResume(t *testing.T) {
+	t.Run("partial destination", func(t *testing.T) {
+		toPath := filepath.Join(t.TempDir(), "out.txt")
+		require.NoError(t, Copy(inputPath, toPath, 100, 300))
+
+		progress := &progressRecorder{}
+		err := CopyWithOptions(inputPath, toPath, 100, 1000, Options{Resume: true, Progress: progress})
+		require.NoError(t, err)
+
+		requireFileEqual(t, "testdata/out_offset100_limit1000.txt", toPath)
+		require.Equal(t, [2]int64{300, 1000}, progress.reports[0])
+		require.Equal(t, [2]int64{1000, 1000}, progress.reports[len(progress.reports)-1])
+	})
+
+	t.Run("missing destination", func(t *testing.T) {
+		toPath := filepath.Join(t.TempDir(), "out.txt")
+
+		err := CopyWithOptions(inputPath, toPath, 0, 0, Options{Resume: true})
+		require.NoError(t, err)
+
+		requireFileEqual(t, "testdata/out_offset0_limit0.txt", toPath)
+	})
+
+	t.Run("complete destination", func(t *testing.T) {
+		toPath := filepath.Join(t.TempDir(), "out.txt")
+		require.NoError(t, Copy(inputPath, toPath, 0, 10))
+
+		err := CopyWithOptions(inputPath, toPath, 0, 10, Options{Resume: true})
+		require.NoError(t, err)
+
+		requireFileEqual(t, "testdata/out_offset0_limit10.txt", toPath)
+	})
+
+	t.Run("destination too large", func(t *testing.T) {
+		toPath := filepath.Join(t.TempDir(), "out.txt")
+		require.NoError(t, Copy(inputPath, toPath, 0, 1000))
+
+		err := CopyWithOptions(inputPath, toPath, 0, 10, Options{Resume: true})
+		require.ErrorIs(t, err, ErrDestinationTooLarge)
+	})
+}
+
+func TestCopyProgress(t *testing.T) {
+	toPath := filepath.Join(t.TempDir(), "out.txt")
+	progress := &progressRecorder{}
+
+	err := CopyWithOptions(inputPath, toPath, 100, 1000, Options{Progress: progress})
+	require.NoError(t, err)
+
+	require.Equal(t, [2]int64{0, 1000}, progress.reports[0])
+	require.Equal(t, [2]int64{1000, 1000}, progress.reports[len(progress.reports)-1])
+	for i := 1; i < len(progress.reports); i++ {
+		require.GreaterOrEqual(t, progress.reports[i][0], progress.reports[i-1][0])
+	}
+}
+
+func requireFileEqual(t *testing.T, expectedPath, actualPath string) {
+	t.Helper()
+
+	expected, err := os.ReadFile(expectedPath)
+	require.NoError(t, err)
+	actual, err := os.ReadFile(actualPath)
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
+
+func requireDirFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	actual := make([]string, 0, len(entries))
+	for _, e := range entries {
+		actual = append(actual, e.Name())
+	}
+	require.ElementsMatch(t, names, actual)
 }
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+const fastCopyChunk = 4 << 20
+
+// errNoMoreData is returned by nextData when the rest of a file is a hole.
+var errNoMoreData = errors.New("no more data")
+
+// fastCopy copies n bytes from src at srcOffset to dst at dstOffset skipping holes of src,
+// so the destination stays sparse. Data segments are copied with (*os.File).ReadFrom,
+// which uses copy_file_range or sendfile on Linux and falls back to a buffered copy.
+func fastCopy(dst, src *os.File, srcOffset, dstOffset, n int64, report func(copied int64)) error {
+	end := srcOffset + n
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() < end {
+		return errSourceTruncated
+	}
+
+	for pos := srcOffset; pos < end; {
+		dataStart, dataEnd, err := nextData(src, pos)
+		if errors.Is(err, errNoMoreData) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if dataStart >= end {
+			break
+		}
+		dataEnd = min(dataEnd, end)
+
+		if err := copySegment(dst, src, dataStart, dstOffset+dataStart-srcOffset, dataEnd-dataStart, func(copied int64) {
+			report(dstOffset + dataStart - srcOffset + copied)
+		}); err != nil {
+			return err
+		}
+
+		pos = dataEnd
+	}
+
+	// extend the destination if the copied range ends with a hole
+	if err := dst.Truncate(dstOffset + n); err != nil {
+		return err
+	}
+	report(dstOffset + n)
+
+	return nil
+}
+
+func copySegment(dst, src *os.File, srcOffset, dstOffset, n int64, report func(copied int64)) error {
+	if _, err := src.Seek(srcOffset, io.SeekStart); err != nil {
+		return err
+	}
+
+	if _, err := dst.Seek(dstOffset, io.SeekStart); err != nil {
+		return err
+	}
+
+	var copied int64
+	for copied < n {
+		written, err := dst.ReadFrom(&io.LimitedReader{R: src, N: min(n-copied, fastCopyChunk)})
+		if err != nil {
+			return err
+		}
+
+		if written == 0 {
+			return errSourceTruncated
+		}
+
+		copied += written
+		report(copied)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"os"
+	"syscall"
+)
+
+const (
+	seekData = 3 // SEEK_DATA
+	seekHole = 4 // SEEK_HOLE
+)
+
+// nextData returns the bounds of the first data segment of f starting at or after offset.
+func nextData(f *os.File, offset int64) (start, end int64, err error) {
+	start, err = f.Seek(offset, seekData)
+	switch {
+	case errors.Is(err, syscall.ENXIO):
+		return 0, 0, errNoMoreData
+	case errors.Is(err, syscall.EINVAL), errors.Is(err, syscall.EOPNOTSUPP):
+		// the file system doesn't report holes, everything is data
+		return offset, math.MaxInt64, nil
+	case err != nil:
+		return 0, 0, err
+	}
+
+	end, err = f.Seek(start, seekHole)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return start, end, nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package main
+
+import (
+	"math"
+	"os"
+)
+
+// nextData treats the whole file as data, holes are detected on Linux only.
+func nextData(_ *os.File, offset int64) (start, end int64, err error) {
+	return offset, math.MaxInt64, nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// sparseFile creates a file of size bytes with data written at the given offsets.
+func sparseFile(t testing.TB, size int64, data map[int64][]byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "sparse.bin")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	require.NoError(t, f.Truncate(size))
+	for offset, b := range data {
+		_, err := f.WriteAt(b, offset)
+		require.NoError(t, err)
+	}
+
+	return path
+}
+
+func allocatedBytes(t *testing.T, path string) int64 {
+	t.Helper()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		t.Skip("allocated size is unknown")
+	}
+
+	return stat.Blocks * 512
+}
+
+func TestFastCopyMatchesBuffered(t *testing.T) {
+	const blockSize = 4096
+
+	sparse := sparseFile(t, 10*blockSize, map[int64][]byte{
+		blockSize:       []byte("first data block"),
+		5*blockSize - 3: []byte("data across the block boundary"),
+		9 * blockSize:   []byte("last block"),
+	})
+
+	sources := map[string][]int64{
+		inputPath: {0, 1, 100, 4095, 4096, 6000, 6617},
+		sparse:    {0, 1, blockSize - 1, blockSize, 2 * blockSize, 5*blockSize - 1, 9*blockSize + 5, 10 * blockSize},
+	}
+	limits := []int64{0, 1, 10, 1000, blockSize, 3 * blockSize, 100000}
+
+	for fromPath, offsets := range sources {
+		for _, offset := range offsets {
+			for _, limit := range limits {
+				name := fmt.Sprintf("%s offset %d limit %d", filepath.Base(fromPath), offset, limit)
+				t.Run(name, func(t *testing.T) {
+					dir := t.TempDir()
+					buffered := filepath.Join(dir, "buffered")
+					fast := filepath.Join(dir, "fast")
+
+					require.NoError(t, CopyWithOptions(fromPath, buffered, offset, limit, Options{Buffered: true}))
+					require.NoError(t, CopyWithOptions(fromPath, fast, offset, limit, Options{}))
+
+					requireFileEqual(t, buffered, fast)
+				})
+			}
+		}
+	}
+
+	t.Run("resume", func(t *testing.T) {
+		dir := t.TempDir()
+		buffered := filepath.Join(dir, "buffered")
+		fast := filepath.Join(dir, "fast")
+
+		require.NoError(t, CopyWithOptions(sparse, buffered, 10, 0, Options{Buffered: true}))
+		require.NoError(t, Copy(sparse, fast, 10, 3*blockSize))
+		require.NoError(t, CopyWithOptions(sparse, fast, 10, 0, Options{Resume: true}))
+
+		requireFileEqual(t, buffered, fast)
+	})
+}
+
+func TestFastCopyPreservesHoles(t *testing.T) {
+	const size = 64 << 20
+
+	fromPath := sparseFile(t, size, map[int64][]byte{
+		0:         []byte("header"),
+		size / 2:  []byte("middle"),
+		size - 10: []byte("trailer"),
+	})
+	if allocatedBytes(t, fromPath) >= size {
+		t.Skip("file system doesn't support sparse files")
+	}
+
+	toPath := filepath.Join(t.TempDir(), "out.bin")
+	require.NoError(t, Copy(fromPath, toPath, 0, 0))
+
+	requireFileEqual(t, fromPath, toPath)
+	require.Less(t, allocatedBytes(t, toPath), int64(size/4))
+}
+
+func BenchmarkCopy(b *testing.B) {
+	const size = 64 << 20
+
+	data := make([]byte, size)
+	_, err := rand.Read(data)
+	require.NoError(b, err)
+
+	fromPath := filepath.Join(b.TempDir(), "in.bin")
+	require.NoError(b, os.WriteFile(fromPath, data, 0o644))
+
+	sparsePath := sparseFile(b, size, map[int64][]byte{0: data[:1<<20], size / 2: data[:1<<20]})
+
+	benchmarks := []struct {
+		name     string
+		fromPath string
+		opts     Options
+	}{
+		{name: "buffered", fromPath: fromPath, opts: Options{Buffered: true}},
+		{name: "fast", fromPath: fromPath},
+		{name: "parallel 4", fromPath: fromPath, opts: Options{Parallel: 4}},
+		{name: "sparse buffered", fromPath: sparsePath, opts: Options{Buffered: true}},
+		{name: "sparse fast", fromPath: sparsePath},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			toPath := filepath.Join(b.TempDir(), "out.bin")
+			b.SetBytes(size)
+
+			for i := 0; i < b.N; i++ {
+				if err := CopyWithOptions(bm.fromPath, toPath, 0, 0, bm.opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
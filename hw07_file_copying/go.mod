@@ -1,3 +1,14 @@
 module github.com/fixme_my_friend/hw07_file_copying
 
 go 1.22
+
+require (
+	github.com/klauspost/compress v1.18.0
+	github.com/stretchr/testify v1.7.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
@@ -2,11 +2,20 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"os"
 )
 
 var (
 	from, to      string
 	limit, offset int64
+	resume        bool
+	buffered      bool
+	hashName      string
+	expected      string
+	compress      string
+	decompress    string
+	parallel      int
 )
 
 func init() {
@@ -14,9 +23,66 @@ func init() {
 	flag.StringVar(&to, "to", "", "file to write to")
 	flag.Int64Var(&limit, "limit", 0, "limit of bytes to copy")
 	flag.Int64Var(&offset, "offset", 0, "offset in input file")
+	flag.BoolVar(&resume, "resume", false, "continue a partial copy to an existing file")
+	flag.BoolVar(&buffered, "buffered", false, "copy through a userland buffer, don't preserve holes")
+	flag.StringVar(&hashName, "hash", "", "compute checksum of copied bytes: sha256 or crc32")
+	flag.StringVar(&expected, "verify", "", "expected hex checksum of copied bytes (sha256 if -hash is not set)")
+	flag.StringVar(&compress, "compress", "", "compress copied bytes: gzip or zstd")
+	flag.StringVar(&decompress, "decompress", "", "decompress copied bytes: gzip or zstd")
+	flag.IntVar(&parallel, "parallel", 1, "number of parts of a regular file copied concurrently")
 }
 
 func main() {
 	flag.Parse()
-	// Place your code here.
+
+	opts := Options{
+		Resume:   resume,
+		Buffered: buffered,
+		Expected: expected,
+		Parallel: parallel,
+	}
+
+	if compress != "" {
+		codec, err := LookupCodec(compress)
+		if err != nil {
+			fail(err)
+		}
+		opts.Compress = codec
+	}
+	if decompress != "" {
+		codec, err := LookupCodec(decompress)
+		if err != nil {
+			fail(err)
+		}
+		opts.Decompress = codec
+	}
+
+	if hashName == "" && expected != "" {
+		hashName = "sha256"
+	}
+	if hashName != "" {
+		h, err := NewHash(hashName)
+		if err != nil {
+			fail(err)
+		}
+		opts.Hash = h
+	}
+
+	bar := newProgressBar(os.Stderr)
+	opts.Progress = bar
+	err := CopyWithOptions(from, to, offset, limit, opts)
+	bar.Finish()
+
+	if err != nil {
+		fail(err)
+	}
+
+	if opts.Hash != nil {
+		fmt.Printf("%s  %s\n", Sum(opts.Hash), to)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
 }
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+const parallelBufferSize = 1 << 20
+
+var ErrParallelUnsupported = errors.New(
+	"parallel copy is supported for regular files without resume, hash and compression")
+
+// parallelCopy splits n bytes of src starting at offset into k parts and copies them
+// to the beginning of dst concurrently. The first error stops all parts.
+func parallelCopy(dst, src *os.File, offset, n int64, k int, report func(copied int64)) error {
+	if err := dst.Truncate(n); err != nil {
+		return err
+	}
+
+	var (
+		wg      sync.WaitGroup
+		failed  atomic.Bool
+		errOnce sync.Once
+		err     error
+
+		mu     sync.Mutex
+		copied int64
+	)
+
+	fail := func(e error) {
+		errOnce.Do(func() { err = e })
+		failed.Store(true)
+	}
+
+	partSize := (n + int64(k) - 1) / int64(k)
+	for start := int64(0); start < n; start += partSize {
+		size := min(partSize, n-start)
+
+		wg.Add(1)
+		go func(start, size int64) {
+			defer wg.Done()
+
+			buf := make([]byte, min(size, parallelBufferSize))
+			for pos := start; pos < start+size; {
+				if failed.Load() {
+					return
+				}
+
+				r, readErr := src.ReadAt(buf[:min(int64(len(buf)), start+size-pos)], offset+pos)
+				if r > 0 {
+					if _, err := dst.WriteAt(buf[:r], pos); err != nil {
+						fail(err)
+						return
+					}
+
+					pos += int64(r)
+
+					mu.Lock()
+					copied += int64(r)
+					report(copied)
+					mu.Unlock()
+				}
+
+				switch {
+				case errors.Is(readErr, io.EOF) && pos < start+size:
+					fail(errSourceTruncated)
+					return
+				case readErr != nil && !errors.Is(readErr, io.EOF):
+					fail(readErr)
+					return
+				}
+			}
+		}(start, size)
+	}
+
+	wg.Wait()
+
+	return err
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type syncProgressRecorder struct {
+	mu sync.Mutex
+	progressRecorder
+}
+
+func (r *syncProgressRecorder) Report(copied, total int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.progressRecorder.Report(copied, total)
+}
+
+func TestParallelCopy(t *testing.T) {
+	offsets := []int64{0, 1, 100, 4096, 6000, 6616, 6617}
+	limits := []int64{0, 1, 10, 1000, 3001, 10000}
+	parts := []int{2, 3, 8, 64}
+
+	for _, offset := range offsets {
+		for _, limit := range limits {
+			for _, k := range parts {
+				t.Run(fmt.Sprintf("offset %d limit %d parallel %d", offset, limit, k), func(t *testing.T) {
+					dir := t.TempDir()
+					sequential := filepath.Join(dir, "sequential")
+					parallel := filepath.Join(dir, "parallel")
+
+					require.NoError(t, Copy(inputPath, sequential, offset, limit))
+					require.NoError(t, CopyWithOptions(inputPath, parallel, offset, limit, Options{Parallel: k}))
+
+					requireFileEqual(t, sequential, parallel)
+				})
+			}
+		}
+	}
+
+	t.Run("progress", func(t *testing.T) {
+		toPath := filepath.Join(t.TempDir(), "out.txt")
+		progress := &syncProgressRecorder{}
+
+		require.NoError(t, CopyWithOptions(inputPath, toPath, 100, 1000, Options{Parallel: 4, Progress: progress}))
+
+		require.Equal(t, [2]int64{1000, 1000}, progress.reports[len(progress.reports)-1])
+	})
+
+	t.Run("destination is replaced", func(t *testing.T) {
+		dir := t.TempDir()
+		toPath := filepath.Join(dir, "out.txt")
+		require.NoError(t, os.WriteFile(toPath, make([]byte, 10000), 0o644))
+
+		require.NoError(t, CopyWithOptions(inputPath, toPath, 0, 10, Options{Parallel: 4}))
+
+		requireFileEqual(t, "testdata/out_offset0_limit10.txt", toPath)
+		requireDirFiles(t, dir, "out.txt")
+	})
+
+	t.Run("unsupported options", func(t *testing.T) {
+		h, err := NewHash("crc32")
+		require.NoError(t, err)
+		codec, err := LookupCodec("gzip")
+		require.NoError(t, err)
+
+		for name, opts := range map[string]Options{
+			"resume":   {Parallel: 2, Resume: true},
+			"hash":     {Parallel: 2, Hash: h},
+			"compress": {Parallel: 2, Compress: codec},
+		} {
+			toPath := filepath.Join(t.TempDir(), "out.txt")
+
+			err := CopyWithOptions(inputPath, toPath, 0, 0, opts)
+			require.ErrorIs(t, err, ErrParallelUnsupported, name)
+		}
+	})
+
+	t.Run("non-regular source", func(t *testing.T) {
+		toPath := filepath.Join(t.TempDir(), "out.txt")
+
+		err := CopyWithOptions("/dev/urandom", toPath, 0, 100, Options{Parallel: 2})
+		require.ErrorIs(t, err, ErrParallelUnsupported)
+		require.NoFileExists(t, toPath)
+	})
+}
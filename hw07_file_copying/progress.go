@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const progressBarWidth = 50
+
+// progressBar draws a single-line terminal progress bar.
+type progressBar struct {
+	w       io.Writer
+	percent int
+}
+
+func newProgressBar(w io.Writer) *progressBar {
+	return &progressBar{w: w, percent: -1}
+}
+
+func (p *progressBar) Report(copied, total int64) {
+	percent := 100
+	if total > 0 {
+		percent = int(copied * 100 / total)
+	}
+
+	if percent == p.percent {
+		return
+	}
+	p.percent = percent
+
+	filled := percent * progressBarWidth / 100
+	fmt.Fprintf(p.w, "\r[%s%s] %3d%% %d/%d bytes",
+		strings.Repeat("=", filled), strings.Repeat(" ", progressBarWidth-filled), percent, copied, total)
+}
+
+// Finish moves the cursor to the next line after the bar.
+func (p *progressBar) Finish() {
+	fmt.Fprintln(p.w)
+}
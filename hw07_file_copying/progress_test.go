@@ -0,0 +1,27 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressBar(t *testing.T) {
+	out := &bytes.Buffer{}
+	bar := newProgressBar(out)
+
+	bar.Report(0, 200)
+	bar.Report(1, 200)
+	bar.Report(100, 200)
+	bar.Report(200, 200)
+	bar.Finish()
+
+	lines := strings.Split(out.String(), "\r")[1:]
+	require.Equal(t, []string{
+		"[" + strings.Repeat(" ", 50) + "]   0% 0/200 bytes",
+		"[" + strings.Repeat("=", 25) + strings.Repeat(" ", 25) + "]  50% 100/200 bytes",
+		"[" + strings.Repeat("=", 50) + "] 100% 200/200 bytes\n",
+	}, lines)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	ErrUnsupportedCodec    = errors.New("unsupported codec")
+	ErrResumeWithTransform = errors.New("resume is not supported with compression")
+)
+
+// Codec compresses and decompresses data of a single format.
+type Codec struct {
+	Name      string
+	NewWriter func(w io.Writer) (io.WriteCloser, error)
+	NewReader func(r io.Reader) (io.ReadCloser, error)
+}
+
+var gzipCodec = Codec{
+	Name: "gzip",
+	NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+		return gzip.NewWriter(w), nil
+	},
+	NewReader: func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+}
+
+var zstdCodec = Codec{
+	Name: "zstd",
+	NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+		return zstd.NewWriter(w)
+	},
+	NewReader: func(r io.Reader) (io.ReadCloser, error) {
+		d, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	},
+}
+
+// LookupCodec returns a built-in codec by its name: gzip or zstd.
+// Codecs of other formats can be provided by the caller.
+func LookupCodec(name string) (*Codec, error) {
+	var codec Codec
+	switch name {
+	case gzipCodec.Name:
+		codec = gzipCodec
+	case zstdCodec.Name:
+		codec = zstdCodec
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedCodec, name)
+	}
+
+	return &codec, nil
+}
+
+// transformCopy copies src to dst, decompressing and compressing data on the way if requested.
+func transformCopy(dst io.Writer, src io.Reader, opts Options) error {
+	if opts.Hash != nil {
+		dst = io.MultiWriter(dst, opts.Hash)
+	}
+
+	if opts.Decompress != nil {
+		r, err := opts.Decompress.NewReader(src)
+		if err != nil {
+			return fmt.Errorf("%s: %w", opts.Decompress.Name, err)
+		}
+		defer r.Close()
+
+		src = r
+	}
+
+	if opts.Compress == nil {
+		_, err := io.Copy(dst, src)
+		return err
+	}
+
+	w, err := opts.Compress.NewWriter(dst)
+	if err != nil {
+		return fmt.Errorf("%s: %w", opts.Compress.Name, err)
+	}
+
+	if _, err := io.Copy(w, src); err != nil {
+		w.Close()
+		return err
+	}
+
+	return w.Close()
+}
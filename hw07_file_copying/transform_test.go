@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func gunzipFile(t *testing.T, path string) []byte {
+	t.Helper()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	r, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	return data
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	compressed := &bytes.Buffer{}
+	w := gzip.NewWriter(compressed)
+	_, err := w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return compressed.Bytes()
+}
+
+func TestCopyTransform(t *testing.T) {
+	gzipCodec, err := LookupCodec("gzip")
+	require.NoError(t, err)
+
+	expected, err := os.ReadFile("testdata/out_offset100_limit1000.txt")
+	require.NoError(t, err)
+
+	t.Run("compress range", func(t *testing.T) {
+		toPath := filepath.Join(t.TempDir(), "out.txt.gz")
+		progress := &progressRecorder{}
+
+		err := CopyWithOptions(inputPath, toPath, 100, 1000, Options{Compress: gzipCodec, Progress: progress})
+		require.NoError(t, err)
+
+		require.Equal(t, expected, gunzipFile(t, toPath))
+		require.Equal(t, [2]int64{1000, 1000}, progress.reports[len(progress.reports)-1])
+	})
+
+	t.Run("decompress range", func(t *testing.T) {
+		compressed := &bytes.Buffer{}
+		w := gzip.NewWriter(compressed)
+		_, err := w.Write(expected)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		prefix := []byte("some log lines before the archive\n")
+		data := append(append(append([]byte{}, prefix...), compressed.Bytes()...), []byte("and after it\n")...)
+		fromPath := filepath.Join(t.TempDir(), "in.bin")
+		require.NoError(t, os.WriteFile(fromPath, data, 0o644))
+
+		toPath := filepath.Join(t.TempDir(), "out.txt")
+		progress := &progressRecorder{}
+
+		err = CopyWithOptions(fromPath, toPath, int64(len(prefix)), int64(compressed.Len()),
+			Options{Decompress: gzipCodec, Progress: progress})
+		require.NoError(t, err)
+
+		requireFileEqual(t, "testdata/out_offset100_limit1000.txt", toPath)
+		last := progress.reports[len(progress.reports)-1]
+		require.Equal(t, int64(compressed.Len()), last[1])
+	})
+
+	t.Run("round trip from named pipe", func(t *testing.T) {
+		input, err := os.ReadFile(inputPath)
+		require.NoError(t, err)
+
+		dir := t.TempDir()
+		compressedPath := filepath.Join(dir, "out.gz")
+		toPath := filepath.Join(dir, "out.txt")
+
+		require.NoError(t, CopyWithOptions(namedPipe(t, input), compressedPath, 100, 1000, Options{Compress: gzipCodec}))
+		require.NoError(t, CopyWithOptions(compressedPath, toPath, 0, 0, Options{Decompress: gzipCodec}))
+
+		requireFileEqual(t, "testdata/out_offset100_limit1000.txt", toPath)
+	})
+
+	for _, name := range []string{"gzip", "zstd"} {
+		t.Run("round trip with "+name, func(t *testing.T) {
+			codec, err := LookupCodec(name)
+			require.NoError(t, err)
+
+			dir := t.TempDir()
+			compressedPath := filepath.Join(dir, "out."+name)
+			progress := &progressRecorder{}
+			err = CopyWithOptions(inputPath, compressedPath, 100, 1000, Options{Compress: codec, Progress: progress})
+			require.NoError(t, err)
+			require.Equal(t, [2]int64{1000, 1000}, progress.reports[len(progress.reports)-1])
+
+			// the archive is copied out of the middle of a file
+			compressed, err := os.ReadFile(compressedPath)
+			require.NoError(t, err)
+			prefix := []byte("some log lines before the archive\n")
+			data := append(append(append([]byte{}, prefix...), compressed...), []byte("and after it\n")...)
+			fromPath := filepath.Join(dir, "in.bin")
+			require.NoError(t, os.WriteFile(fromPath, data, 0o644))
+
+			toPath := filepath.Join(dir, "out.txt")
+			progress = &progressRecorder{}
+			err = CopyWithOptions(fromPath, toPath, int64(len(prefix)), int64(len(compressed)),
+				Options{Decompress: codec, Progress: progress})
+			require.NoError(t, err)
+
+			requireFileEqual(t, "testdata/out_offset100_limit1000.txt", toPath)
+			total := int64(len(compressed))
+			require.Equal(t, [2]int64{total, total}, progress.reports[len(progress.reports)-1])
+		})
+	}
+
+	t.Run("checksum of compressed output", func(t *testing.T) {
+		h, err := NewHash("sha256")
+		require.NoError(t, err)
+
+		toPath := filepath.Join(t.TempDir(), "out.txt.gz")
+		require.NoError(t, CopyWithOptions(inputPath, toPath, 0, 0, Options{Compress: gzipCodec, Hash: h}))
+
+		written, err := os.ReadFile(toPath)
+		require.NoError(t, err)
+		sum := sha256.Sum256(written)
+		require.Equal(t, hex.EncodeToString(sum[:]), Sum(h))
+	})
+
+	t.Run("invalid compressed data", func(t *testing.T) {
+		dir := t.TempDir()
+		toPath := filepath.Join(dir, "out.txt")
+
+		err := CopyWithOptions(inputPath, toPath, 0, 0, Options{Decompress: gzipCodec})
+		require.ErrorIs(t, err, gzip.ErrHeader)
+		requireDirFiles(t, dir)
+	})
+
+	t.Run("source truncated between members", func(t *testing.T) {
+		first := gzipBytes(t, expected[:500])
+		compressed := append(append([]byte{}, first...), gzipBytes(t, expected[500:])...)
+
+		dir := t.TempDir()
+		fromPath := filepath.Join(dir, "in.gz")
+		require.NoError(t, os.WriteFile(fromPath, compressed, 0o644))
+		f, err := os.Open(fromPath)
+		require.NoError(t, err)
+		defer f.Close()
+		src, err := newSource(f, 0, 0)
+		require.NoError(t, err)
+
+		// the source shrinks after its size is known, the rest is a valid gzip stream
+		require.NoError(t, os.Truncate(fromPath, int64(len(first))))
+
+		dst, err := os.Create(filepath.Join(dir, "out.txt"))
+		require.NoError(t, err)
+		defer dst.Close()
+
+		err = copyRange(src, dst, 0, 0, Options{Decompress: gzipCodec})
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		toPath := filepath.Join(t.TempDir(), "out.txt.gz")
+
+		err := CopyWithOptions(inputPath, toPath, 0, 0, Options{Compress: gzipCodec, Resume: true})
+		require.ErrorIs(t, err, ErrResumeWithTransform)
+	})
+
+	t.Run("unsupported codec", func(t *testing.T) {
+		_, err := LookupCodec("brotli")
+		require.ErrorIs(t, err, ErrUnsupportedCodec)
+	})
+}
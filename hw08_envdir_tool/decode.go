@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidTarget   = errors.New("target must be a non-nil pointer to a struct")
+	ErrUnsupportedType = errors.New("unsupported field type")
+	ErrMissingVariable = errors.New("required variable is missing")
+	ErrInvalidValue    = errors.New("invalid value")
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// VarError describes a missing or invalid variable decoded into the struct field.
+type VarError struct {
+	Name  string
+	Field string
+	Err   error
+}
+
+func (e *VarError) Error() string {
+	return fmt.Sprintf("%s (field %s): %v", e.Name, e.Field, e.Err)
+}
+
+func (e *VarError) Unwrap() error {
+	return e.Err
+}
+
+// Decode sets fields of the struct pointed by target from variables of env.
+// A field is read from the variable named in its tag `env:"NAME"`, `env:"NAME,required"` makes
+// the variable mandatory (other comma separated options are ignored), `default:"value"` is used
+// if the variable is not set. Struct fields without the tag are decoded recursively. Supported types
+// are strings, bools, integers, floats, time.Duration, encoding.TextUnmarshaler implementations
+// and slices of them (comma separated values).
+// A variable to remove is considered not set.
+//
+// All missing and invalid variables are reported at once, as *VarError joined with errors.Join.
+// Invalid targets and field types are reported with ErrInvalidTarget and ErrUnsupportedType.
+func Decode(env Environment, target interface{}) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: %T", ErrInvalidTarget, target)
+	}
+
+	var errs []error
+	if err := decodeStruct(env, v.Elem(), "", &errs); err != nil {
+		return err
+	}
+
+	return errors.Join(errs...)
+}
+
+func decodeStruct(env Environment, v reflect.Value, path string, errs *[]error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldPath := path + field.Name
+		tag, ok := field.Tag.Lookup("env")
+		if !ok {
+			if field.Type.Kind() == reflect.Struct && !isScalar(field.Type) {
+				if err := decodeStruct(env, v.Field(i), fieldPath+".", errs); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+
+		if !isScalar(field.Type) && !(field.Type.Kind() == reflect.Slice && isScalar(field.Type.Elem())) {
+			return fmt.Errorf("%w: field %s of type %s", ErrUnsupportedType, fieldPath, field.Type)
+		}
+
+		raw, set := lookupVar(env, name)
+		if !set {
+			raw, set = field.Tag.Lookup("default")
+		}
+
+		if !set {
+			if hasOption(options, "required") {
+				*errs = append(*errs, &VarError{Name: name, Field: fieldPath, Err: ErrMissingVariable})
+			}
+			continue
+		}
+
+		if err := setField(v.Field(i), raw); err != nil {
+			err = fmt.Errorf("%w %q: %w", ErrInvalidValue, raw, err)
+			*errs = append(*errs, &VarError{Name: name, Field: fieldPath, Err: err})
+		}
+	}
+
+	return nil
+}
+
+func hasOption(options, option string) bool {
+	for _, o := range strings.Split(options, ",") {
+		if strings.TrimSpace(o) == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+func lookupVar(env Environment, name string) (string, bool) {
+	value, ok := env[name]
+	if !ok || value.NeedRemove {
+		return "", false
+	}
+
+	return value.Value, true
+}
+
+func isScalar(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+
+	switch t.Kind() { //nolint:exhaustive
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+func setField(v reflect.Value, raw string) error {
+	if v.Kind() != reflect.Slice || v.Addr().Type().Implements(textUnmarshalerType) {
+		return setScalar(v, raw)
+	}
+
+	var parts []string
+	if strings.TrimSpace(raw) != "" {
+		parts = strings.Split(raw, ",")
+	}
+
+	slice := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+	for i, part := range parts {
+		if err := setScalar(slice.Index(i), strings.TrimSpace(part)); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+	v.Set(slice)
+
+	return nil
+}
+
+func setScalar(v reflect.Value, raw string) (err error) {
+	defer func() {
+		// the value is already in the message of VarError, the cause is enough
+		var numErr *strconv.NumError
+		if errors.As(err, &numErr) {
+			err = numErr.Err
+		}
+	}()
+
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(raw))
+	}
+
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, v.Type())
+	}
+
+	return nil
+}
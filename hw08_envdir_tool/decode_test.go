@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type serverConfig struct {
+	Host    string        `env:"HOST" default:"localhost"`
+	Port    uint16        `env:"PORT,required"`
+	Debug   bool          `env:"DEBUG"`
+	Timeout time.Duration `env:"TIMEOUT" default:"5s"`
+	Origins []string      `env:"ORIGINS"`
+	Ports   []int         `env:"EXTRA_PORTS" default:"8081, 8082"`
+	Ratio   float64       `env:"RATIO"`
+	IP      net.IP        `env:"IP"`
+	Ignored string        `env:"-"`
+	DB      struct {
+		URL      string `env:"DB_URL,required"`
+		PoolSize int    `env:"DB_POOL_SIZE" default:"10"`
+	}
+	internal int //nolint:unused
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("values and defaults", func(t *testing.T) {
+		env := Environment{
+			"PORT":    {Value: "8080"},
+			"DEBUG":   {Value: "true"},
+			"TIMEOUT": {NeedRemove: true},
+			"ORIGINS": {Value: "a.com, b.com"},
+			"RATIO":   {Value: "0.5"},
+			"IP":      {Value: "10.0.0.1"},
+			"DB_URL":  {Value: "postgres://db"},
+			"Ignored": {Value: "x"},
+			"-":       {Value: "x"},
+		}
+
+		var cfg serverConfig
+		require.NoError(t, Decode(env, &cfg))
+
+		expected := serverConfig{
+			Host:    "localhost",
+			Port:    8080,
+			Debug:   true,
+			Timeout: 5 * time.Second,
+			Origins: []string{"a.com", "b.com"},
+			Ports:   []int{8081, 8082},
+			Ratio:   0.5,
+			IP:      net.IPv4(10, 0, 0, 1),
+		}
+		expected.DB.URL = "postgres://db"
+		expected.DB.PoolSize = 10
+		require.Equal(t, expected, cfg)
+	})
+
+	t.Run("empty values", func(t *testing.T) {
+		cfg := serverConfig{Origins: []string{"old"}}
+		require.NoError(t, Decode(Environment{
+			"HOST":        {Value: ""},
+			"PORT":        {Value: "1"},
+			"ORIGINS":     {Value: ""},
+			"EXTRA_PORTS": {Value: " "},
+			"DB_URL":      {Value: ""},
+		}, &cfg))
+
+		require.Equal(t, "", cfg.Host, "an empty value is set, the default is not used")
+		require.Empty(t, cfg.Origins)
+		require.Empty(t, cfg.Ports)
+	})
+
+	t.Run("all errors at once", func(t *testing.T) {
+		env := Environment{
+			"PORT":         {Value: "70000"},
+			"DEBUG":        {Value: "maybe"},
+			"TIMEOUT":      {Value: "5"},
+			"EXTRA_PORTS":  {Value: "1,x"},
+			"IP":           {Value: "not ip"},
+			"DB_POOL_SIZE": {Value: "ten"},
+		}
+
+		var cfg serverConfig
+		err := Decode(env, &cfg)
+		require.ErrorIs(t, err, ErrMissingVariable)
+		require.ErrorIs(t, err, ErrInvalidValue)
+
+		var varErrs []string
+		for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+			var varErr *VarError
+			require.True(t, errors.As(e, &varErr))
+			varErrs = append(varErrs, varErr.Name)
+		}
+		require.Equal(t, []string{"PORT", "DEBUG", "TIMEOUT", "EXTRA_PORTS", "IP", "DB_URL", "DB_POOL_SIZE"}, varErrs)
+
+		require.Contains(t, err.Error(), `PORT (field Port): invalid value "70000": value out of range`)
+		require.Contains(t, err.Error(), `EXTRA_PORTS (field Ports): invalid value "1,x": element 1: invalid syntax`)
+		require.Contains(t, err.Error(), `DB_URL (field DB.URL): required variable is missing`)
+	})
+
+	t.Run("required among other options", func(t *testing.T) {
+		var cfg struct {
+			Token  string `env:"TOKEN,required,secret"`
+			Secret string `env:"SECRET,secret,required"`
+			Name   string `env:"NAME,secret"`
+		}
+
+		err := Decode(Environment{"SECRET": {Value: "s"}}, &cfg)
+		require.ErrorIs(t, err, ErrMissingVariable)
+		require.EqualError(t, err, "TOKEN (field Token): required variable is missing")
+		require.Equal(t, "s", cfg.Secret)
+	})
+
+	t.Run("invalid target", func(t *testing.T) {
+		var cfg serverConfig
+		for _, target := range []interface{}{nil, cfg, (*serverConfig)(nil), new(int)} {
+			require.ErrorIs(t, Decode(nil, target), ErrInvalidTarget)
+		}
+
+		var unsupported struct {
+			Map map[string]string `env:"MAP"`
+		}
+		require.ErrorIs(t, Decode(Environment{}, &unsupported), ErrUnsupportedType)
+	})
+}
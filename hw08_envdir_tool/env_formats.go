@@ -0,0 +1,342 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrSyntax = errors.New("syntax error")
+
+// ReadFile reads variables from a dotenv (.env or any other extension), JSON (.json) or YAML (.yaml, .yml) file.
+func ReadFile(path string) (Environment, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var env Environment
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		env, err = ParseJSON(f)
+	case ".yaml", ".yml":
+		env, err = ParseYAML(f)
+	default:
+		env, err = ParseDotenv(f)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return env, nil
+}
+
+// ReadEnv reads variables from an env directory with ReadDir or from a file with ReadFile.
+func ReadEnv(path string) (Environment, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return ReadDir(path)
+	}
+
+	return ReadFile(path)
+}
+
+// setVar sets a parsed value as is: unlike envdir files, parsed formats express line breaks
+// and trailing spaces directly, so the envdir rules are not applied.
+func setVar(env Environment, name string, value EnvValue) error {
+	if name == "" || strings.ContainsAny(name, "= \t") {
+		return fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+
+	if strings.IndexByte(value.Value, 0) >= 0 {
+		return fmt.Errorf("%w: %s: NUL bytes are not allowed in values", ErrSyntax, name)
+	}
+
+	env[name] = value
+
+	return nil
+}
+
+// ParseDotenv parses lines in NAME=value format with optional export prefix.
+// Values can be single quoted (literal), double quoted (with escapes, may span lines) or unquoted
+// (a comment starting with " #" is cut off, spaces are trimmed). An unquoted empty value means
+// removing the variable, "" sets it to an empty string.
+func ParseDotenv(r io.Reader) (Environment, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	env := make(Environment)
+	p := &dotenvParser{data: string(data), line: 1}
+
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return env, nil
+		}
+
+		line := p.line
+		name, value, err := p.assignment()
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrSyntax, line, err)
+		}
+
+		if err := setVar(env, name, value); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+}
+
+type dotenvParser struct {
+	data string
+	pos  int
+	line int
+}
+
+func (p *dotenvParser) eof() bool {
+	return p.pos >= len(p.data)
+}
+
+func (p *dotenvParser) next() byte {
+	c := p.data[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+
+	return c
+}
+
+// skipBlank skips whitespace, empty lines and comments.
+func (p *dotenvParser) skipBlank() {
+	for !p.eof() {
+		switch p.data[p.pos] {
+		case ' ', '\t', '\r', '\n':
+			p.next()
+		case '#':
+			p.skipLine()
+		default:
+			return
+		}
+	}
+}
+
+func (p *dotenvParser) skipLine() {
+	for !p.eof() {
+		if p.next() == '\n' {
+			return
+		}
+	}
+}
+
+func (p *dotenvParser) skipSpaces() {
+	for !p.eof() && (p.data[p.pos] == ' ' || p.data[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) restOfLine() string {
+	start := p.pos
+	for !p.eof() && p.data[p.pos] != '\n' {
+		p.pos++
+	}
+
+	return strings.TrimSuffix(p.data[start:p.pos], "\r")
+}
+
+func (p *dotenvParser) assignment() (name string, value EnvValue, err error) {
+	if rest, ok := strings.CutPrefix(p.data[p.pos:], "export"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+		p.pos += len("export")
+		p.skipSpaces()
+	}
+
+	eq := strings.IndexByte(p.data[p.pos:], '=')
+	nl := strings.IndexByte(p.data[p.pos:], '\n')
+	if eq < 0 || (nl >= 0 && nl < eq) {
+		return "", EnvValue{}, errors.New("expected NAME=value")
+	}
+
+	name = strings.TrimSpace(p.data[p.pos : p.pos+eq])
+	p.pos += eq + 1
+
+	p.skipSpaces()
+
+	if p.eof() {
+		return name, EnvValue{NeedRemove: true}, nil
+	}
+
+	switch p.data[p.pos] {
+	case '\'':
+		value.Value, err = p.singleQuoted()
+	case '"':
+		value.Value, err = p.doubleQuoted()
+	default:
+		raw := p.restOfLine()
+		if i := strings.Index(raw, " #"); i >= 0 {
+			raw = raw[:i]
+		}
+		if raw = strings.TrimSpace(raw); raw == "" {
+			return name, EnvValue{NeedRemove: true}, nil
+		}
+		return name, EnvValue{Value: raw}, nil
+	}
+	if err != nil {
+		return "", EnvValue{}, err
+	}
+
+	if rest := strings.TrimSpace(p.restOfLine()); rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", EnvValue{}, fmt.Errorf("unexpected %q after quoted value", rest)
+	}
+
+	return name, value, nil
+}
+
+func (p *dotenvParser) singleQuoted() (string, error) {
+	p.next()
+	end := strings.IndexByte(p.data[p.pos:], '\'')
+	if end < 0 {
+		return "", errors.New("unterminated single quoted value")
+	}
+
+	value := p.data[p.pos : p.pos+end]
+	for i := 0; i <= end; i++ {
+		p.next()
+	}
+
+	return value, nil
+}
+
+func (p *dotenvParser) doubleQuoted() (string, error) {
+	p.next()
+
+	var sb strings.Builder
+	for !p.eof() {
+		c := p.next()
+		switch c {
+		case '"':
+			return sb.String(), nil
+		case '\\':
+			if p.eof() {
+				return "", errors.New("unterminated escape sequence")
+			}
+			switch e := p.next(); e {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			case '\\', '"', '$':
+				sb.WriteByte(e)
+			default:
+				return "", fmt.Errorf("unknown escape sequence \\%c", e)
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return "", errors.New("unterminated double quoted value")
+}
+
+// ParseJSON parses a single object with string, number, boolean or null values.
+// Null means removing the variable, "" sets it to an empty string.
+func ParseJSON(r io.Reader) (Environment, error) {
+	var raw map[string]interface{}
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSyntax, err)
+	}
+
+	if decoder.More() {
+		return nil, fmt.Errorf("%w: unexpected data after the object", ErrSyntax)
+	}
+
+	env := make(Environment, len(raw))
+	for name, value := range raw {
+		var val EnvValue
+		switch v := value.(type) {
+		case nil:
+			val.NeedRemove = true
+		case string:
+			val.Value = v
+		case json.Number:
+			val.Value = v.String()
+		case bool:
+			val.Value = strconv.FormatBool(v)
+		default:
+			return nil, fmt.Errorf("%w: %q: only scalar values are supported", ErrSyntax, name)
+		}
+
+		if err := setVar(env, name, val); err != nil {
+			return nil, err
+		}
+	}
+
+	return env, nil
+}
+
+// ParseYAML parses a flat YAML mapping of scalars, only the first document is read.
+// Values keep their text, so 1.10 is not turned into 1.1. Null, ~ and empty plain values
+// mean removing the variable, "" sets it to an empty string.
+func ParseYAML(r io.Reader) (Environment, error) {
+	env := make(Environment)
+
+	var doc yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return env, nil
+		}
+		return nil, fmt.Errorf("%w: %w", ErrSyntax, err)
+	}
+
+	if len(doc.Content) == 0 {
+		return env, nil
+	}
+
+	root := resolveAlias(doc.Content[0])
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%w: line %d: expected a mapping", ErrSyntax, root.Line)
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := resolveAlias(root.Content[i]), resolveAlias(root.Content[i+1])
+		if key.Kind != yaml.ScalarNode || value.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("%w: line %d: only scalar values are supported", ErrSyntax, key.Line)
+		}
+
+		val := EnvValue{Value: value.Value, NeedRemove: value.Tag == "!!null"}
+		if val.NeedRemove {
+			val.Value = ""
+		}
+
+		if err := setVar(env, key.Value, val); err != nil {
+			return nil, fmt.Errorf("line %d: %w", key.Line, err)
+		}
+	}
+
+	return env, nil
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.AliasNode {
+		return node.Alias
+	}
+
+	return node
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDotenv(t *testing.T) {
+	input := `# comment
+FOO=foo
+export BAR = bar value   
+  BAZ=baz # inline comment
+EMPTY=
+SINGLE='single $quoted \n value '
+DOUBLE="double\tquoted\nvalue \"with\" escapes"
+MULTILINE="first line
+second line"
+TRAILING="value  "
+LINE_BREAK="value\n"
+QUOTED_EMPTY=""
+HASH=value#not a comment
+export=variable named export
+`
+
+	env, err := ParseDotenv(strings.NewReader(input))
+	require.NoError(t, err)
+
+	require.Equal(t, Environment{
+		"FOO":          {Value: "foo"},
+		"BAR":          {Value: "bar value"},
+		"BAZ":          {Value: "baz"},
+		"EMPTY":        {NeedRemove: true},
+		"SINGLE":       {Value: `single $quoted \n value `},
+		"DOUBLE":       {Value: "double\tquoted\nvalue \"with\" escapes"},
+		"MULTILINE":    {Value: "first line\nsecond line"},
+		"TRAILING":     {Value: "value  "},
+		"LINE_BREAK":   {Value: "value\n"},
+		"QUOTED_EMPTY": {Value: ""},
+		"HASH":         {Value: "value#not a comment"},
+		"export":       {Value: "variable named export"},
+	}, env)
+
+	errorCases := map[string]string{
+		"no assignment":       "FOO\nBAR=bar",
+		"unterminated single": "FOO='foo",
+		"unterminated double": "FOO=\"foo",
+		"unknown escape":      `FOO="\q"`,
+		"zero escape":         `FOO="\0"`,
+		"text after quotes":   `FOO="foo" bar`,
+	}
+	for name, input := range errorCases {
+		_, err := ParseDotenv(strings.NewReader(input))
+		require.ErrorIs(t, err, ErrSyntax, name)
+	}
+
+	_, err = ParseDotenv(strings.NewReader("\n\nBAD NAME=foo"))
+	require.ErrorIs(t, err, ErrInvalidName)
+	require.Contains(t, err.Error(), "line 3")
+}
+
+func TestParseJSON(t *testing.T) {
+	input := `{
+		"FOO": "foo  ",
+		"NUMBER": 42.5,
+		"BOOL": true,
+		"NULL": null,
+		"EMPTY": "",
+		"MULTILINE": "first\nsecond"
+	}`
+
+	env, err := ParseJSON(strings.NewReader(input))
+	require.NoError(t, err)
+
+	require.Equal(t, Environment{
+		"FOO":       {Value: "foo  "},
+		"NUMBER":    {Value: "42.5"},
+		"BOOL":      {Value: "true"},
+		"NULL":      {NeedRemove: true},
+		"EMPTY":     {Value: ""},
+		"MULTILINE": {Value: "first\nsecond"},
+	}, env)
+
+	errorCases := []string{
+		`{"FOO": {"BAR": "baz"}}`, `{"FOO": [1]}`, `["FOO"]`, `{"FOO": `,
+		`{"FOO": "a\u0000b"}`, `{"FOO": "bar"} x`, `{"FOO": "bar"} {}`,
+	}
+	for _, input := range errorCases {
+		_, err := ParseJSON(strings.NewReader(input))
+		require.ErrorIs(t, err, ErrSyntax, input)
+	}
+
+	_, err = ParseJSON(strings.NewReader(`{"FOO=BAR": "baz"}`))
+	require.ErrorIs(t, err, ErrInvalidName)
+}
+
+func TestParseYAML(t *testing.T) {
+	input := `---
+# comment
+FOO: foo
+BAR: bar value # comment
+URL: http://example.com:8080/path
+SINGLE: 'it''s # not a comment'
+DOUBLE: "double\tquoted\nvalue"
+"QUOTED_KEY": value
+TILDE: ~
+NULL: null
+EMPTY:
+QUOTED_EMPTY: ""
+TRAILING: "value  "
+`
+
+	env, err := ParseYAML(strings.NewReader(input))
+	require.NoError(t, err)
+
+	require.Equal(t, Environment{
+		"FOO":          {Value: "foo"},
+		"BAR":          {Value: "bar value"},
+		"URL":          {Value: "http://example.com:8080/path"},
+		"SINGLE":       {Value: "it's # not a comment"},
+		"DOUBLE":       {Value: "double\tquoted\nvalue"},
+		"QUOTED_KEY":   {Value: "value"},
+		"TILDE":        {NeedRemove: true},
+		"NULL":         {NeedRemove: true},
+		"EMPTY":        {NeedRemove: true},
+		"QUOTED_EMPTY": {Value: ""},
+		"TRAILING":     {Value: "value  "},
+	}, env)
+
+	errorCases := map[string]string{
+		"nested":              "FOO:\n  BAR: baz",
+		"list":                "- FOO",
+		"flow mapping":        "FOO: {BAR: baz}",
+		"sequence value":      "FOO: [bar]",
+		"no colon":            "FOO",
+		"unterminated quotes": "FOO: 'bar",
+		"text after quotes":   `FOO: "bar" baz`,
+		"zero escape":         `FOO: "line\0next"`,
+	}
+	for name, input := range errorCases {
+		_, err := ParseYAML(strings.NewReader(input))
+		require.ErrorIs(t, err, ErrSyntax, name)
+	}
+
+	t.Run("quoted escapes", func(t *testing.T) {
+		input := `ESCAPES: "tab\tx\x41\u00e9\"q\"\\"
+FOLDED_QUOTES: "first
+  second"
+VERSION: 1.10
+`
+
+		env, err := ParseYAML(strings.NewReader(input))
+		require.NoError(t, err)
+		require.Equal(t, Environment{
+			"ESCAPES":       {Value: "tab\txA\u00e9\"q\"\\"},
+			"FOLDED_QUOTES": {Value: "first second"},
+			"VERSION":       {Value: "1.10"},
+		}, env)
+	})
+
+	t.Run("block scalars", func(t *testing.T) {
+		input := `LITERAL: |
+  first line
+  second line
+FOLDED: >
+  folded
+  text
+KEEP: |+
+  kept
+
+STRIP: |-
+  stripped
+`
+
+		env, err := ParseYAML(strings.NewReader(input))
+		require.NoError(t, err)
+		require.Equal(t, Environment{
+			"LITERAL": {Value: "first line\nsecond line\n"},
+			"FOLDED":  {Value: "folded text\n"},
+			"KEEP":    {Value: "kept\n\n"},
+			"STRIP":   {Value: "stripped"},
+		}, env)
+	})
+
+	t.Run("empty document", func(t *testing.T) {
+		env, err := ParseYAML(strings.NewReader("# nothing here\n"))
+		require.NoError(t, err)
+		require.Empty(t, env)
+	})
+}
+
+func TestReadEnv(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"base.env":    "FOO=dotenv\nBAR=dotenv\n",
+		"config.json": `{"FOO": "json", "BAZ": "json"}`,
+		"local.yml":   "FOO: yaml\nBAR:\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	env, err := ReadEnv(filepath.Join(dir, "config.json"))
+	require.NoError(t, err)
+	require.Equal(t, Environment{"FOO": {Value: "json"}, "BAZ": {Value: "json"}}, env)
+
+	env, err = ReadLayers(
+		filepath.Join(dir, "base.env"),
+		filepath.Join(dir, "config.json"),
+		"testdata/env",
+		filepath.Join(dir, "local.yml"),
+	)
+	require.NoError(t, err)
+	require.Equal(t, Environment{"FOO": {Value: "yaml"}, "BAR": {NeedRemove: true}, "BAZ": {Value: "json"}}, Environment{
+		"FOO": env["FOO"], "BAR": env["BAR"], "BAZ": env["BAZ"],
+	})
+	require.Equal(t, EnvValue{Value: `"hello"`}, env["HELLO"])
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0o644))
+	_, err = ReadEnv(filepath.Join(dir, "broken.json"))
+	require.ErrorIs(t, err, ErrSyntax)
+	require.Contains(t, err.Error(), "broken.json")
+}
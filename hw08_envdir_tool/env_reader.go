@@ -1,16 +1,134 @@
 package main
 
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrInvalidName = errors.New("invalid variable name")
+
 type Environment map[string]EnvValue
 
 // EnvValue helps to distinguish between empty files and files with the first empty line.
+// Secret values are redacted when the environment is printed.
 type EnvValue struct {
 	Value      string
 	NeedRemove bool
+	Secret     bool
 }
 
 // ReadDir reads a specified directory and returns map of env variables.
 // Variables represented as files where filename is name of variable, file first line is a value.
 func ReadDir(dir string) (Environment, error) {
-	// Place your code here
-	return nil, nil
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	env := make(Environment, len(entries))
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		name := entry.Name()
+		if strings.Contains(name, "=") {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidName, name)
+		}
+
+		value, err := readValue(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		env[name] = value
+	}
+
+	return env, nil
+}
+
+func readValue(path string) (EnvValue, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return EnvValue{}, err
+	}
+	defer f.Close()
+
+	return readValueFrom(f)
+}
+
+func readValueFrom(r io.Reader) (EnvValue, error) {
+	line, err := bufio.NewReader(r).ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return EnvValue{}, err
+	}
+
+	if len(line) == 0 {
+		return EnvValue{NeedRemove: true}, nil
+	}
+
+	return EnvValue{Value: cleanValue(line)}, nil
+}
+
+// cleanValue strips the line break and trailing spaces and replaces terminal zeros with line breaks.
+func cleanValue(line []byte) string {
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	line = bytes.TrimRight(line, " \t")
+	line = bytes.ReplaceAll(line, []byte("\x00"), []byte("\n"))
+
+	return string(line)
+}
+
+// Merge combines environments, values of later ones override earlier.
+// A variable to remove in a later environment removes the variable set in earlier ones.
+func Merge(envs ...Environment) Environment {
+	merged := make(Environment)
+	for _, env := range envs {
+		for name, value := range env {
+			merged[name] = value
+		}
+	}
+
+	return merged
+}
+
+// ReadLayers reads env directories or files with ReadEnv and merges them as layers, the last one is the top.
+func ReadLayers(paths ...string) (Environment, error) {
+	env, _, err := ReadLayersSources(paths...)
+	return env, err
+}
+
+// ReadLayersSources works as ReadLayers and also returns files the resulting variables were read from.
+func ReadLayersSources(paths ...string) (Environment, map[string]string, error) {
+	envs := make([]Environment, 0, len(paths))
+	sources := make(map[string]string)
+
+	for _, path := range paths {
+		env, err := ReadEnv(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		envs = append(envs, env)
+
+		isDir := isDirectory(path)
+		for name := range env {
+			if isDir {
+				sources[name] = filepath.Join(path, name)
+			} else {
+				sources[name] = path
+			}
+		}
+	}
+
+	return Merge(envs...), sources, nil
+}
+
+func isDirectory(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
 }
@@ -1,7 +1,112 @@
 package main
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// envDir creates a temporary env directory with files of the given content.
+func envDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	return dir
+}
 
 func TestReadDir(t *testing.T) {
-	// Place your code here
+	t.Run("testdata", func(t *testing.T) {
+		env, err := ReadDir("testdata/env")
+		require.NoError(t, err)
+
+		require.Equal(t, Environment{
+			"BAR":   {Value: "bar"},
+			"EMPTY": {Value: ""},
+			"FOO":   {Value: "   foo\nwith new line"},
+			"HELLO": {Value: `"hello"`},
+			"UNSET": {NeedRemove: true},
+		}, env)
+	})
+
+	t.Run("trailing spaces and tabs", func(t *testing.T) {
+		env, err := ReadDir(envDir(t, map[string]string{
+			"SPACES":  "value \t \nsecond",
+			"LEADING": "\t value",
+			"NEWLINE": "\n",
+		}))
+		require.NoError(t, err)
+
+		require.Equal(t, Environment{
+			"SPACES":  {Value: "value"},
+			"LEADING": {Value: "\t value"},
+			"NEWLINE": {Value: ""},
+		}, env)
+	})
+
+	t.Run("subdirectories are ignored", func(t *testing.T) {
+		dir := envDir(t, map[string]string{"FOO": "foo"})
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "SUBDIR"), 0o755))
+
+		env, err := ReadDir(dir)
+		require.NoError(t, err)
+		require.Equal(t, Environment{"FOO": {Value: "foo"}}, env)
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		_, err := ReadDir(envDir(t, map[string]string{"FOO=BAR": "foo"}))
+		require.ErrorIs(t, err, ErrInvalidName)
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		_, err := ReadDir(filepath.Join(t.TempDir(), "missing"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestMerge(t *testing.T) {
+	base := Environment{
+		"FOO":   {Value: "foo"},
+		"BAR":   {Value: "bar"},
+		"UNSET": {NeedRemove: true},
+	}
+	upper := Environment{
+		"FOO":   {Value: "new foo"},
+		"BAR":   {NeedRemove: true},
+		"UNSET": {Value: "set again"},
+		"NEW":   {Value: "new"},
+	}
+
+	require.Equal(t, Environment{
+		"FOO":   {Value: "new foo"},
+		"BAR":   {NeedRemove: true},
+		"UNSET": {Value: "set again"},
+		"NEW":   {Value: "new"},
+	}, Merge(base, upper))
+
+	require.Equal(t, Environment{}, Merge())
+	require.Equal(t, base, Merge(base))
+}
+
+func TestReadLayers(t *testing.T) {
+	lower := envDir(t, map[string]string{"FOO": "lower foo", "BAR": "lower bar", "BAZ": "lower baz"})
+	middle := envDir(t, map[string]string{"FOO": "middle foo", "BAR": ""})
+	upper := envDir(t, map[string]string{"FOO": "upper foo"})
+
+	env, err := ReadLayers(lower, middle, upper)
+	require.NoError(t, err)
+
+	require.Equal(t, Environment{
+		"FOO": {Value: "upper foo"},
+		"BAR": {NeedRemove: true},
+		"BAZ": {Value: "lower baz"},
+	}, env)
+
+	_, err = ReadLayers(lower, filepath.Join(t.TempDir(), "missing"))
+	require.ErrorIs(t, err, os.ErrNotExist)
 }
@@ -1,7 +1,138 @@
 package main
 
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+const (
+	failedToRunCode = 111
+	// signaledCode is added to the signal number when the command is killed by a signal, as shells do.
+	signaledCode = 128
+)
+
+// forwardedSignals are delivered to the running command instead of terminating envdir.
+var forwardedSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP}
+
 // RunCmd runs a command + arguments (cmd) with environment variables from env.
+// SIGINT, SIGTERM and SIGHUP received while the command runs are forwarded to it.
+// The return code is the exit code of the command or 128+signal if the command is killed by a signal.
 func RunCmd(cmd []string, env Environment) (returnCode int) {
-	// Place your code here.
-	return
+	signals := make(chan os.Signal, len(forwardedSignals))
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
+
+	command, err := startCmd(cmd, env)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return failedToRunCode
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-signals:
+				// the command may have exited already, there is nobody to deliver the signal to
+				_ = command.Process.Signal(sig)
+			}
+		}
+	}()
+
+	err = command.Wait()
+	close(done)
+
+	return exitCode(err)
+}
+
+// startCmd starts the command with standard streams of envdir.
+func startCmd(cmd []string, env Environment) (*exec.Cmd, error) {
+	if len(cmd) == 0 {
+		return nil, errors.New("command is not specified")
+	}
+
+	//nolint:gosec
+	command := exec.Command(cmd[0], cmd[1:]...)
+	command.Env = env.Apply(os.Environ())
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+
+	if err := command.Start(); err != nil {
+		return nil, err
+	}
+
+	return command, nil
+}
+
+// ExecCmd replaces the current process with the command as daemontools' envdir does.
+// It returns only if the command can't be executed.
+func ExecCmd(cmd []string, env Environment) (returnCode int) {
+	if len(cmd) == 0 {
+		fmt.Fprintln(os.Stderr, "command is not specified")
+		return failedToRunCode
+	}
+
+	path, err := exec.LookPath(cmd[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return failedToRunCode
+	}
+
+	//nolint:gosec
+	err = syscall.Exec(path, cmd, env.Apply(os.Environ()))
+	fmt.Fprintf(os.Stderr, "exec %s: %v\n", cmd[0], err)
+
+	return failedToRunCode
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		fmt.Fprintln(os.Stderr, err)
+		return failedToRunCode
+	}
+
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return signaledCode + int(status.Signal())
+	}
+
+	return exitErr.ExitCode()
+}
+
+// Apply returns environ (in os.Environ format) with variables of env set or removed.
+func (e Environment) Apply(environ []string) []string {
+	result := make([]string, 0, len(environ)+len(e))
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, ok := e[name]; !ok {
+			result = append(result, kv)
+		}
+	}
+
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if value := e[name]; !value.NeedRemove {
+			result = append(result, name+"="+value.Value)
+		}
+	}
+
+	return result
 }
@@ -1,7 +1,153 @@
 package main
 
-import "testing"
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
 
 func TestRunCmd(t *testing.T) {
-	// Place your code here
+	t.Run("exit code", func(t *testing.T) {
+		require.Equal(t, 0, RunCmd([]string{"true"}, nil))
+		require.Equal(t, 1, RunCmd([]string{"false"}, nil))
+		require.Equal(t, 42, RunCmd([]string{"/bin/sh", "-c", "exit 42"}, nil))
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		t.Setenv("REPLACED", "old")
+		t.Setenv("REMOVED", "old")
+		t.Setenv("KEPT", "kept")
+
+		out := filepath.Join(t.TempDir(), "out.txt")
+		env := Environment{
+			"REPLACED": {Value: "new"},
+			"REMOVED":  {NeedRemove: true},
+			"ADDED":    {Value: "added"},
+		}
+
+		code := RunCmd([]string{
+			"/bin/sh", "-c", `echo "$REPLACED|${REMOVED-unset}|$KEPT|$ADDED" > "$0"`, out,
+		}, env)
+		require.Equal(t, 0, code)
+
+		result, err := os.ReadFile(out)
+		require.NoError(t, err)
+		require.Equal(t, "new|unset|kept|added\n", string(result))
+	})
+
+	t.Run("killed by signal", func(t *testing.T) {
+		require.Equal(t, 128+int(syscall.SIGKILL), RunCmd([]string{"/bin/sh", "-c", "kill -KILL $$"}, nil))
+		require.Equal(t, 128+int(syscall.SIGTERM), RunCmd(helperCommand("die"), nil))
+	})
+
+	t.Run("command not found", func(t *testing.T) {
+		require.Equal(t, failedToRunCode, RunCmd([]string{"/nonexistent/command"}, nil))
+	})
+
+	t.Run("empty command", func(t *testing.T) {
+		require.Equal(t, failedToRunCode, RunCmd(nil, nil))
+	})
+}
+
+func TestRunCmdForwardsSignals(t *testing.T) {
+	for _, sig := range []syscall.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP} {
+		sig := sig
+		t.Run(sig.String(), func(t *testing.T) {
+			ready := filepath.Join(t.TempDir(), "ready")
+
+			code := make(chan int, 1)
+			go func() {
+				code <- RunCmd(helperCommand("trap", ready), nil)
+			}()
+
+			require.Eventually(t, func() bool {
+				_, err := os.Stat(ready)
+				return err == nil
+			}, 5*time.Second, 10*time.Millisecond, "the helper has not started")
+
+			// the signal is sent to the test process itself, envdir must not die but pass it on
+			require.NoError(t, syscall.Kill(os.Getpid(), sig))
+
+			select {
+			case c := <-code:
+				require.Equal(t, int(sig), c, "the helper exits with the number of the received signal")
+			case <-time.After(5 * time.Second):
+				t.Fatal("the signal has not been forwarded")
+			}
+		})
+	}
+}
+
+func TestExecCmd(t *testing.T) {
+	command := exec.Command(os.Args[0], helperCommand("exec")[1:]...)
+	command.Env = append(os.Environ(), "EXEC_FOO=old", "EXEC_REMOVED=old")
+
+	out, err := command.CombinedOutput()
+	require.NoError(t, err)
+	require.Equal(t,
+		fmt.Sprintf("%d new unset\n", command.Process.Pid), string(out),
+		"the command replaces the helper process keeping its pid",
+	)
+
+	require.Equal(t, failedToRunCode, ExecCmd([]string{"/nonexistent/command"}, nil))
+	require.Equal(t, failedToRunCode, ExecCmd(nil, nil))
+}
+
+// helperCommand returns a command running the test binary as a helper, see TestHelperProcess.
+func helperCommand(args ...string) []string {
+	return append([]string{os.Args[0], "-test.run=^TestHelperProcess$", "--"}, args...)
+}
+
+// TestHelperProcess is not a real test, it is run by other tests as a child command:
+//   - die kills itself with SIGTERM;
+//   - trap FILE creates FILE when ready and exits with the number of the first received signal;
+//   - exec replaces itself by a shell printing its pid and variables with ExecCmd.
+func TestHelperProcess(t *testing.T) {
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	if len(args) < 2 {
+		return
+	}
+
+	switch mode := args[1]; mode {
+	case "die":
+		signal.Reset(syscall.SIGTERM)
+		_ = syscall.Kill(os.Getpid(), syscall.SIGTERM)
+		time.Sleep(time.Minute)
+	case "trap":
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+		if err := os.WriteFile(args[2], nil, 0o644); err != nil {
+			os.Exit(1)
+		}
+		sig := <-signals
+		os.Exit(int(sig.(syscall.Signal)))
+	case "exec":
+		env := Environment{"EXEC_FOO": {Value: "new"}, "EXEC_REMOVED": {NeedRemove: true}}
+		os.Exit(ExecCmd([]string{"sh", "-c", `echo "$$ $EXEC_FOO ${EXEC_REMOVED-unset}"`}, env))
+	default:
+		fmt.Fprintln(os.Stderr, "unknown helper mode", mode)
+		os.Exit(2)
+	}
+}
+
+func TestEnvironmentApply(t *testing.T) {
+	env := Environment{
+		"FOO":   {Value: "new foo"},
+		"UNSET": {NeedRemove: true},
+		"NEW":   {Value: "a=b"},
+	}
+
+	result := env.Apply([]string{"FOO=foo", "UNSET=value", "PATH=/bin", "FOOBAR=x"})
+
+	require.Equal(t, []string{"PATH=/bin", "FOOBAR=x", "FOO=new foo", "NEW=a=b"}, result)
 }
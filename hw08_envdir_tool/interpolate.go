@@ -0,0 +1,269 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrRequiredVariable = errors.New("required variable is not set")
+	ErrBadSubstitution  = errors.New("bad substitution")
+)
+
+// CycleError is returned when variables reference each other in a loop.
+type CycleError struct {
+	// Chain lists variables of the cycle, the first one is repeated at the end.
+	Chain []string
+	// Files lists files the variables were read from, if known.
+	Files []string
+}
+
+func (e *CycleError) Error() string {
+	parts := make([]string, 0, len(e.Chain))
+	for i, name := range e.Chain {
+		if i < len(e.Files) && e.Files[i] != "" {
+			name = fmt.Sprintf("%s (%s)", name, e.Files[i])
+		}
+		parts = append(parts, name)
+	}
+
+	return "interpolation cycle: " + strings.Join(parts, " -> ")
+}
+
+// Expander substitutes ${VAR}, ${VAR:-default} and ${VAR:?error} in values of an Environment.
+// Variables are resolved against other entries of the Environment first and then against Lookup.
+// A variable referencing itself, like PATH=/opt/bin:${PATH}, gets the value of Lookup.
+// Use $${ to get a literal ${. Values referencing secrets become secrets too.
+type Expander struct {
+	// Lookup resolves variables of the parent environment, os.LookupEnv if nil.
+	Lookup func(name string) (string, bool)
+	// Sources maps variables to files they were read from, it is used in errors only.
+	Sources map[string]string
+}
+
+// Expand substitutes variables with the parent environment of the process.
+func Expand(env Environment) (Environment, error) {
+	return Expander{}.Expand(env)
+}
+
+func (x Expander) Expand(env Environment) (Environment, error) {
+	if x.Lookup == nil {
+		x.Lookup = os.LookupEnv
+	}
+
+	e := &expansion{
+		Expander: x,
+		env:      env,
+		result:   make(Environment, len(env)),
+		visiting: make(map[string]bool),
+		tainted:  make(map[string]bool),
+	}
+
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if env[name].NeedRemove {
+			e.result[name] = env[name]
+			continue
+		}
+
+		if _, err := e.resolve(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return e.result, nil
+}
+
+type expansion struct {
+	Expander
+	env      Environment
+	result   Environment
+	visiting map[string]bool
+	tainted  map[string]bool // variables referencing secrets
+	stack    []string
+}
+
+func (e *expansion) resolve(name string) (string, error) {
+	if value, ok := e.result[name]; ok {
+		return value.Value, nil
+	}
+
+	if e.visiting[name] {
+		return "", e.cycle(name)
+	}
+
+	e.visiting[name] = true
+	e.stack = append(e.stack, name)
+
+	value, err := e.expand(name, e.env[name].Value)
+	if err != nil {
+		return "", err
+	}
+
+	e.stack = e.stack[:len(e.stack)-1]
+	delete(e.visiting, name)
+	e.result[name] = EnvValue{Value: value, Secret: e.env[name].Secret || e.tainted[name]}
+
+	return value, nil
+}
+
+func (e *expansion) cycle(name string) error {
+	start := 0
+	for i, n := range e.stack {
+		if n == name {
+			start = i
+			break
+		}
+	}
+
+	err := &CycleError{Chain: append(append([]string{}, e.stack[start:]...), name)}
+	if len(e.Sources) > 0 {
+		for _, n := range err.Chain {
+			err.Files = append(err.Files, e.Sources[n])
+		}
+	}
+
+	return err
+}
+
+// lookup resolves variable name referenced by the variable owner.
+func (e *expansion) lookup(owner, name string) (string, bool, error) {
+	if value, ok := e.env[name]; ok && name != owner {
+		if value.NeedRemove {
+			return "", false, nil
+		}
+
+		resolved, err := e.resolve(name)
+		return resolved, true, err
+	}
+
+	value, ok := e.Lookup(name)
+	return value, ok, nil
+}
+
+// expand substitutes variables in value of the variable owner.
+func (e *expansion) expand(owner, value string) (string, error) {
+	var sb strings.Builder
+
+	for {
+		i := strings.Index(value, "${")
+		if i < 0 {
+			sb.WriteString(value)
+			return sb.String(), nil
+		}
+
+		if i > 0 && value[i-1] == '$' {
+			sb.WriteString(value[:i-1] + "${")
+			value = value[i+2:]
+			continue
+		}
+
+		sb.WriteString(value[:i])
+
+		end := matchingBrace(value, i+2)
+		if end < 0 {
+			return "", fmt.Errorf("%w: %s: unterminated ${", ErrBadSubstitution, e.describe(owner))
+		}
+
+		substituted, err := e.substitute(owner, value[i+2:end])
+		if err != nil {
+			return "", err
+		}
+
+		sb.WriteString(substituted)
+		value = value[end+1:]
+	}
+}
+
+// substitute resolves expression of ${expression}.
+func (e *expansion) substitute(owner, expr string) (string, error) {
+	name, op, arg := expr, "", ""
+	if i := strings.Index(expr, ":"); i >= 0 {
+		name, op, arg = expr[:i], expr[i:min(i+2, len(expr))], expr[min(i+2, len(expr)):]
+	}
+
+	if !isValidName(name) || (op != "" && op != ":-" && op != ":?") {
+		return "", fmt.Errorf("%w: %s: ${%s}", ErrBadSubstitution, e.describe(owner), expr)
+	}
+
+	value, ok, err := e.lookup(owner, name)
+	if err != nil {
+		return "", err
+	}
+
+	if e.result[name].Secret {
+		e.tainted[owner] = true
+	}
+
+	if ok && value != "" {
+		return value, nil
+	}
+
+	switch op {
+	case ":-":
+		return e.expand(owner, arg)
+	case ":?":
+		message, err := e.expand(owner, arg)
+		if err != nil {
+			return "", err
+		}
+		if message == "" {
+			message = "is required"
+		}
+		return "", fmt.Errorf("%w: %s referenced by %s: %s", ErrRequiredVariable, name, e.describe(owner), message)
+	default:
+		return value, nil
+	}
+}
+
+func (e *expansion) describe(name string) string {
+	if file := e.Sources[name]; file != "" {
+		return fmt.Sprintf("%s (%s)", name, file)
+	}
+
+	return name
+}
+
+// matchingBrace returns the index of } closing ${ with nested ${...} taken into account.
+func matchingBrace(s string, start int) int {
+	depth := 1
+	for i := start; i < len(s); i++ {
+		switch {
+		case s[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '{':
+			depth++
+			i++
+		}
+	}
+
+	return -1
+}
+
+func isValidName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, c := range name {
+		switch {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
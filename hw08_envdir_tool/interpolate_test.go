@@ -0,0 +1,134 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func parentEnv(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := vars[name]
+		return value, ok
+	}
+}
+
+func TestExpand(t *testing.T) {
+	x := Expander{Lookup: parentEnv(map[string]string{
+		"USER":         "parent-user",
+		"HOST":         "parent-host",
+		"EMPTY_PARENT": "",
+		"REMOVED":      "parent value",
+	})}
+
+	t.Run("references", func(t *testing.T) {
+		env := Environment{
+			"DB_URL":   {Value: "postgres://${DB_USER}@${DB_HOST}:${DB_PORT:-5432}/${DB_NAME:-${USER}}"},
+			"DB_USER":  {Value: "${USER}_app"},
+			"DB_HOST":  {Value: "db.local"},
+			"LITERAL":  {Value: "$USER costs $$5 and $${NOT_EXPANDED}"},
+			"DEFAULTS": {Value: "${EMPTY_PARENT:-empty}|${MISSING:-missing}|${MISSING}|${REMOVED:-removed}"},
+			"REMOVED":  {NeedRemove: true},
+		}
+
+		result, err := x.Expand(env)
+		require.NoError(t, err)
+
+		require.Equal(t, Environment{
+			"DB_URL":   {Value: "postgres://parent-user_app@db.local:5432/parent-user"},
+			"DB_USER":  {Value: "parent-user_app"},
+			"DB_HOST":  {Value: "db.local"},
+			"LITERAL":  {Value: "$USER costs $$5 and ${NOT_EXPANDED}"},
+			"DEFAULTS": {Value: "empty|missing||removed"},
+			"REMOVED":  {NeedRemove: true},
+		}, result)
+	})
+
+	t.Run("required", func(t *testing.T) {
+		_, err := x.Expand(Environment{"URL": {Value: "${TOKEN:?token must be set}"}})
+		require.ErrorIs(t, err, ErrRequiredVariable)
+		require.Contains(t, err.Error(), "TOKEN referenced by URL: token must be set")
+
+		_, err = x.Expand(Environment{"URL": {Value: "${EMPTY_PARENT:?}"}})
+		require.ErrorIs(t, err, ErrRequiredVariable)
+		require.Contains(t, err.Error(), "is required")
+
+		result, err := x.Expand(Environment{"URL": {Value: "${HOST:?}"}})
+		require.NoError(t, err)
+		require.Equal(t, "parent-host", result["URL"].Value)
+	})
+
+	t.Run("bad substitution", func(t *testing.T) {
+		for _, value := range []string{"${", "${FOO", "${}", "${1FOO}", "${FOO-bar}", "${FOO:+bar}", "${FOO BAR}"} {
+			_, err := x.Expand(Environment{"VALUE": {Value: value}})
+			require.ErrorIs(t, err, ErrBadSubstitution, value)
+		}
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		dir := "/etc/env"
+		env := Environment{
+			"A": {Value: "${B}"},
+			"B": {Value: "prefix ${C:-default}"},
+			"C": {Value: "${A}"},
+		}
+		x := Expander{
+			Lookup: parentEnv(nil),
+			Sources: map[string]string{
+				"A": filepath.Join(dir, "A"),
+				"B": filepath.Join(dir, "B"),
+				"C": "/etc/local.env",
+			},
+		}
+
+		_, err := x.Expand(env)
+
+		var cycleErr *CycleError
+		require.ErrorAs(t, err, &cycleErr)
+		require.Equal(t, []string{"A", "B", "C", "A"}, cycleErr.Chain)
+		require.Equal(t, []string{"/etc/env/A", "/etc/env/B", "/etc/local.env", "/etc/env/A"}, cycleErr.Files)
+		require.EqualError(t, err,
+			"interpolation cycle: A (/etc/env/A) -> B (/etc/env/B) -> C (/etc/local.env) -> A (/etc/env/A)")
+	})
+
+	t.Run("self reference extends parent value", func(t *testing.T) {
+		x := Expander{Lookup: parentEnv(map[string]string{"PATH": "/usr/bin:/bin"})}
+		env := Environment{
+			"PATH":    {Value: "/opt/bin:${PATH}"},
+			"SELF":    {Value: "${SELF:-x}"},
+			"COMMAND": {Value: "PATH=${PATH}"},
+		}
+
+		result, err := x.Expand(env)
+		require.NoError(t, err)
+		require.Equal(t, Environment{
+			"PATH":    {Value: "/opt/bin:/usr/bin:/bin"},
+			"SELF":    {Value: "x"},
+			"COMMAND": {Value: "PATH=/opt/bin:/usr/bin:/bin"},
+		}, result)
+	})
+
+	t.Run("sources of layers", func(t *testing.T) {
+		base := envDir(t, map[string]string{"A": "${B}", "B": "b"})
+		upper := envDir(t, map[string]string{"B": "${A}"})
+
+		env, sources, err := ReadLayersSources(base, upper)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"A": filepath.Join(base, "A"), "B": filepath.Join(upper, "B")}, sources)
+
+		_, err = Expander{Lookup: parentEnv(nil), Sources: sources}.Expand(env)
+		var cycleErr *CycleError
+		require.ErrorAs(t, err, &cycleErr)
+		a, b := filepath.Join(base, "A"), filepath.Join(upper, "B")
+		require.Equal(t, []string{a, b, a}, cycleErr.Files)
+	})
+
+	t.Run("parent environment of process", func(t *testing.T) {
+		t.Setenv("ENVDIR_TEST_USER", "process-user")
+
+		result, err := Expand(Environment{"URL": {Value: "${ENVDIR_TEST_USER}@host"}})
+		require.NoError(t, err)
+		require.Equal(t, "process-user@host", result["URL"].Value)
+	})
+}
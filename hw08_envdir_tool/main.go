@@ -1,5 +1,207 @@
 package main
 
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrNoDirs = errors.New("env directory is not specified")
+
+var (
+	dryRun, expand, encrypt, execMode, watch bool
+	layerDirs, secretDirs                    stringList
+	secretKey                                string
+	watchDebounce, watchPoll                 time.Duration
+)
+
+func init() {
+	flag.BoolVar(&dryRun, "dry-run", false,
+		"print the resulting environment with secrets redacted instead of running the command")
+	flag.BoolVar(&expand, "expand", false, "substitute ${VAR} references in values, they are passed literally otherwise")
+	flag.Var(&layerDirs, "dir",
+		"env directory or file, may be repeated to apply layers in order; the arguments are the command then")
+	flag.Var(&secretDirs, "secrets",
+		"env directory of secrets readable by the owner only, applied on top of other layers; may be repeated")
+	flag.StringVar(&secretKey, "secret-key", "", "file with a key to decrypt secrets in *.enc files")
+	flag.BoolVar(&execMode, "exec", false, "replace envdir with the command instead of running it as a child")
+	flag.BoolVar(&watch, "watch", false, "restart the command when env directories or files change")
+	flag.DurationVar(&watchDebounce, "watch-debounce", defaultDebounce, "quiet period after changes before restarting")
+	flag.DurationVar(&watchPoll, "watch-poll", 0, "poll files with the interval instead of using inotify")
+	flag.BoolVar(&encrypt, "encrypt", false, "encrypt stdin to stdout with -secret-key to make a *.enc secret file")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage:\n  %s [flags] dir command [args...]\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "  %s [flags] -dir dir1 -dir dir2 ... [--] command [args...]\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(),
+			"Instead of directories dotenv, JSON (.json) and YAML (.yaml, .yml) files can be used.")
+		flag.PrintDefaults()
+	}
+}
+
 func main() {
-	// Place your code here.
+	flag.Parse()
+
+	if encrypt {
+		if err := runEncrypt(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(failedToRunCode)
+		}
+		return
+	}
+
+	dirs, cmd, err := parseArgs(layerDirs, flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(failedToRunCode)
+	}
+
+	load := func() (Environment, error) {
+		return loadEnvironment(dirs)
+	}
+
+	if watch {
+		if execMode || dryRun {
+			fmt.Fprintln(os.Stderr, "-watch can't be used with -exec or -dry-run")
+			os.Exit(failedToRunCode)
+		}
+
+		os.Exit(WatchCmd(context.Background(), cmd, watchedPaths(dirs), load, WatchOptions{
+			Debounce: watchDebounce,
+			Poll:     watchPoll,
+		}))
+	}
+
+	env, err := load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(failedToRunCode)
+	}
+
+	if dryRun {
+		printEnv(env.Redacted().Apply(os.Environ()))
+		return
+	}
+
+	if execMode {
+		os.Exit(ExecCmd(cmd, env))
+	}
+
+	os.Exit(RunCmd(cmd, env))
+}
+
+// watchedPaths returns the paths which the environment is read from, including the secret key,
+// so rotating the key reloads secrets too.
+func watchedPaths(dirs []string) []string {
+	// dirs share the array of arguments with cmd, so they are copied before appending
+	paths := append(append([]string{}, dirs...), secretDirs...)
+	if secretKey != "" {
+		paths = append(paths, secretKey)
+	}
+
+	return paths
+}
+
+// loadEnvironment reads the environment with readEnvironment and expands variable references if enabled.
+func loadEnvironment(dirs []string) (Environment, error) {
+	env, sources, err := readEnvironment(dirs)
+	if err != nil {
+		return nil, err
+	}
+
+	if !expand {
+		return env, nil
+	}
+
+	return Expander{Sources: sources}.Expand(env)
+}
+
+// readEnvironment reads layers of dirs and then layers of secret directories.
+func readEnvironment(dirs []string) (Environment, map[string]string, error) {
+	env, sources, err := ReadLayersSources(dirs...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(secretDirs) == 0 {
+		return env, sources, nil
+	}
+
+	var key []byte
+	if secretKey != "" {
+		if key, err = ReadKeyFile(secretKey); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, dir := range secretDirs {
+		secrets, err := ReadSecretDir(dir, key)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		env = Merge(env, secrets)
+		for name := range secrets {
+			sources[name] = filepath.Join(dir, name)
+		}
+	}
+
+	return env, sources, nil
+}
+
+func runEncrypt() error {
+	if secretKey == "" {
+		return fmt.Errorf("-encrypt: %w", ErrKeyRequired)
+	}
+
+	key, err := ReadKeyFile(secretKey)
+	if err != nil {
+		return err
+	}
+
+	return encryptTo(os.Stdout, os.Stdin, key)
+}
+
+// parseArgs splits arguments into env directories and a command. Without -dir flags the first
+// argument is the only directory. A "--" may separate directories from the command, later ones
+// are arguments of the command.
+func parseArgs(flagDirs, args []string) (dirs, cmd []string, err error) {
+	dirs = flagDirs
+	if len(dirs) == 0 {
+		if len(args) == 0 || args[0] == "--" {
+			return nil, nil, ErrNoDirs
+		}
+		dirs, args = args[:1], args[1:]
+	}
+
+	if len(args) > 0 && args[0] == "--" {
+		args = args[1:]
+	}
+
+	return dirs, args, nil
+}
+
+// stringList is a flag which may be repeated.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func printEnv(environ []string) {
+	sort.Strings(environ)
+	for _, kv := range environ {
+		fmt.Println(kv)
+	}
 }
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		flagDirs []string
+		args     []string
+		dirs     []string
+		cmd      []string
+	}{
+		{name: "single directory", args: []string{"env", "cmd", "arg"}, dirs: []string{"env"}, cmd: []string{"cmd", "arg"}},
+		{name: "directory only", args: []string{"env"}, dirs: []string{"env"}, cmd: []string{}},
+		{
+			name: "separator after directory",
+			args: []string{"env", "--", "cmd", "--", "arg"},
+			dirs: []string{"env"},
+			cmd:  []string{"cmd", "--", "arg"},
+		},
+		{
+			name: "separator in arguments of command",
+			args: []string{"./env", "grep", "--", "pattern"},
+			dirs: []string{"./env"},
+			cmd:  []string{"grep", "--", "pattern"},
+		},
+		{
+			name:     "layers",
+			flagDirs: []string{"base", "local"},
+			args:     []string{"--", "cmd", "--", "arg"},
+			dirs:     []string{"base", "local"},
+			cmd:      []string{"cmd", "--", "arg"},
+		},
+		{
+			name:     "layers without separator",
+			flagDirs: []string{"base", "local"},
+			args:     []string{"cmd", "arg"},
+			dirs:     []string{"base", "local"},
+			cmd:      []string{"cmd", "arg"},
+		},
+		{
+			name:     "layers without command",
+			flagDirs: []string{"base", "local"},
+			dirs:     []string{"base", "local"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dirs, cmd, err := parseArgs(tc.flagDirs, tc.args)
+			require.NoError(t, err)
+			require.Equal(t, tc.dirs, dirs)
+			require.Equal(t, tc.cmd, cmd)
+		})
+	}
+
+	for _, args := range [][]string{nil, {"--", "cmd"}} {
+		_, _, err := parseArgs(nil, args)
+		require.ErrorIs(t, err, ErrNoDirs)
+	}
+}
+
+func TestLoadEnvironmentExpand(t *testing.T) {
+	t.Setenv("X", "parent")
+	dir := envDir(t, map[string]string{"FOO": "${X}\n", "BAR": "$X-${Y:-default}"})
+
+	env, err := loadEnvironment([]string{dir})
+	require.NoError(t, err)
+	require.Equal(t, Environment{"FOO": {Value: "${X}"}, "BAR": {Value: "$X-${Y:-default}"}}, env)
+
+	expand = true
+	t.Cleanup(func() { expand = false })
+
+	env, err = loadEnvironment([]string{dir})
+	require.NoError(t, err)
+	require.Equal(t, "parent", env["FOO"].Value)
+}
+
+func TestWatchedPaths(t *testing.T) {
+	secretDirs, secretKey = stringList{"secrets"}, "key.hex"
+	t.Cleanup(func() { secretDirs, secretKey = nil, "" })
+
+	args := []string{"base", "local", "cmd"}
+	require.Equal(t, []string{"base", "local", "secrets", "key.hex"}, watchedPaths(args[:2]))
+	require.Equal(t, []string{"base", "local", "cmd"}, args)
+}
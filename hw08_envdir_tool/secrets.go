@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// encryptedExt marks secret files encrypted with AES-256-GCM, the variable name is the file name without it.
+const encryptedExt = ".enc"
+
+// redacted replaces values of secrets in printed environment.
+const redacted = "<redacted>"
+
+var (
+	ErrInsecurePermissions = errors.New("file is readable by group or others")
+	ErrKeyRequired         = errors.New("encrypted secret requires a key")
+	ErrInvalidKey          = errors.New("key must be 32 bytes, raw or hex encoded")
+	ErrDecrypt             = errors.New("cannot decrypt secret")
+)
+
+// ReadSecretDir reads an env directory as ReadDir does, but every file must be readable by its owner only
+// and the variables are marked as secrets. Files with the .enc extension are decrypted with key
+// (see Encrypt), key may be nil if there are no such files.
+func ReadSecretDir(dir string, key []byte) (Environment, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	env := make(Environment, len(entries))
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		name := entry.Name()
+		encrypted := strings.HasSuffix(name, encryptedExt)
+		name = strings.TrimSuffix(name, encryptedExt)
+		if name == "" || strings.Contains(name, "=") {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidName, entry.Name())
+		}
+
+		data, err := readSecretFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		if encrypted {
+			if data, err = Decrypt(key, data); err != nil {
+				return nil, fmt.Errorf("%s: %w", filepath.Join(dir, entry.Name()), err)
+			}
+		}
+
+		value, err := readValueFrom(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		value.Secret = true
+		env[name] = value
+	}
+
+	return env, nil
+}
+
+// ReadKeyFile reads a key for encrypted secrets, the file has the same permission requirements as secrets.
+func ReadKeyFile(path string) ([]byte, error) {
+	data, err := readSecretFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// a raw key may start or end with whitespace bytes, so only a hex encoded key is trimmed
+	if len(data) == 32 {
+		return data, nil
+	}
+
+	key, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("%s: %w", path, ErrInvalidKey)
+	}
+
+	return key, nil
+}
+
+// readSecretFile reads a file checking that it is not readable by group or others.
+// The permissions are checked for the opened file, so it can't be replaced in between.
+func readSecretFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if perm := info.Mode().Perm(); perm&0o044 != 0 {
+		return nil, fmt.Errorf("%s: %w: %04o", path, ErrInsecurePermissions, perm)
+	}
+
+	return io.ReadAll(f)
+}
+
+// Encrypt encrypts plaintext with AES-256-GCM, the random nonce is prepended to the result.
+func Encrypt(key, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decrypt decrypts data produced by Encrypt.
+func Decrypt(key, data []byte) ([]byte, error) {
+	if key == nil {
+		return nil, ErrKeyRequired
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: too short", ErrDecrypt)
+	}
+
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecrypt, err)
+	}
+
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, ErrInvalidKey
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// Redacted returns a copy of the environment with values of secrets replaced.
+func (e Environment) Redacted() Environment {
+	result := make(Environment, len(e))
+	for name, value := range e {
+		if value.Secret && !value.NeedRemove {
+			value.Value = redacted
+		}
+		result[name] = value
+	}
+
+	return result
+}
+
+// encryptTo reads plaintext from r and writes it encrypted for a secret .enc file to w.
+func encryptTo(w io.Writer, r io.Reader, key []byte) error {
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	data, err := Encrypt(key, plaintext)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func secretDir(t *testing.T, files map[string][]byte) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), content, 0o600))
+	}
+
+	return dir
+}
+
+func TestReadSecretDir(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+
+	encrypted, err := Encrypt(key, []byte("s3cret  \nsecond line"))
+	require.NoError(t, err)
+
+	t.Run("plain and encrypted", func(t *testing.T) {
+		dir := secretDir(t, map[string][]byte{
+			"DB_PASSWORD":   []byte("hunter2\n"),
+			"API_TOKEN.enc": encrypted,
+			"UNSET":         nil,
+		})
+
+		env, err := ReadSecretDir(dir, key)
+		require.NoError(t, err)
+		require.Equal(t, Environment{
+			"DB_PASSWORD": {Value: "hunter2", Secret: true},
+			"API_TOKEN":   {Value: "s3cret", Secret: true},
+			"UNSET":       {NeedRemove: true, Secret: true},
+		}, env)
+	})
+
+	t.Run("insecure permissions", func(t *testing.T) {
+		for _, perm := range []os.FileMode{0o640, 0o604, 0o644} {
+			dir := secretDir(t, map[string][]byte{"DB_PASSWORD": []byte("hunter2")})
+			require.NoError(t, os.Chmod(filepath.Join(dir, "DB_PASSWORD"), perm))
+
+			_, err := ReadSecretDir(dir, nil)
+			require.ErrorIs(t, err, ErrInsecurePermissions)
+			require.NotContains(t, err.Error(), "hunter2")
+		}
+	})
+
+	t.Run("wrong or missing key", func(t *testing.T) {
+		dir := secretDir(t, map[string][]byte{"API_TOKEN.enc": encrypted})
+
+		_, err := ReadSecretDir(dir, nil)
+		require.ErrorIs(t, err, ErrKeyRequired)
+
+		_, err = ReadSecretDir(dir, bytes.Repeat([]byte{8}, 32))
+		require.ErrorIs(t, err, ErrDecrypt)
+
+		_, err = ReadSecretDir(dir, []byte("short"))
+		require.ErrorIs(t, err, ErrInvalidKey)
+	})
+
+	t.Run("corrupted", func(t *testing.T) {
+		corrupted := append([]byte{}, encrypted...)
+		corrupted[len(corrupted)-1] ^= 1
+		dir := secretDir(t, map[string][]byte{"API_TOKEN.enc": corrupted, "SHORT.enc": []byte("x")})
+
+		_, err := ReadSecretDir(dir, key)
+		require.ErrorIs(t, err, ErrDecrypt)
+	})
+}
+
+func TestReadKeyFile(t *testing.T) {
+	key := bytes.Repeat([]byte{0xab}, 32)
+	dir := secretDir(t, map[string][]byte{
+		"raw":   key,
+		"hex":   []byte(hex.EncodeToString(key) + "\n"),
+		"short": []byte("abc"),
+	})
+
+	for _, name := range []string{"raw", "hex"} {
+		read, err := ReadKeyFile(filepath.Join(dir, name))
+		require.NoError(t, err, name)
+		require.Equal(t, key, read, name)
+	}
+
+	t.Run("raw key with whitespace bytes", func(t *testing.T) {
+		key := append([]byte{'\n'}, bytes.Repeat([]byte{0xcd}, 30)...)
+		key = append(key, ' ')
+		dir := secretDir(t, map[string][]byte{"key": key})
+
+		read, err := ReadKeyFile(filepath.Join(dir, "key"))
+		require.NoError(t, err)
+		require.Equal(t, key, read)
+	})
+
+	_, err := ReadKeyFile(filepath.Join(dir, "short"))
+	require.ErrorIs(t, err, ErrInvalidKey)
+
+	require.NoError(t, os.Chmod(filepath.Join(dir, "raw"), 0o644))
+	_, err = ReadKeyFile(filepath.Join(dir, "raw"))
+	require.ErrorIs(t, err, ErrInsecurePermissions)
+}
+
+func TestEncryptTo(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+
+	var out bytes.Buffer
+	require.NoError(t, encryptTo(&out, strings.NewReader("token"), key))
+	require.NotContains(t, out.String(), "token")
+
+	plaintext, err := Decrypt(key, out.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, "token", string(plaintext))
+}
+
+func TestRedacted(t *testing.T) {
+	env := Merge(
+		Environment{"USER": {Value: "app"}, "PASSWORD": {Value: "plain"}},
+		Environment{"PASSWORD": {Value: "hunter2", Secret: true}, "TOKEN": {NeedRemove: true, Secret: true}},
+		Environment{"DSN": {Value: "${USER}:${PASSWORD}@db"}, "HOST": {Value: "${USER}.local"}},
+	)
+
+	expanded, err := Expander{Lookup: parentEnv(nil)}.Expand(env)
+	require.NoError(t, err)
+	require.Equal(t, "app:hunter2@db", expanded["DSN"].Value)
+
+	require.Equal(t, Environment{
+		"USER":     {Value: "app"},
+		"HOST":     {Value: "app.local"},
+		"PASSWORD": {Value: redacted, Secret: true},
+		"DSN":      {Value: redacted, Secret: true},
+		"TOKEN":    {NeedRemove: true, Secret: true},
+	}, expanded.Redacted())
+
+	require.Equal(t, "hunter2", expanded["PASSWORD"].Value, "the original environment is not changed")
+	require.Equal(t, []string{"PASSWORD=" + redacted}, Environment{"PASSWORD": expanded["PASSWORD"]}.Redacted().Apply(nil))
+}
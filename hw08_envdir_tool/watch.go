@@ -0,0 +1,314 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultDebounce     = 200 * time.Millisecond
+	defaultPollInterval = time.Second
+	defaultStopTimeout  = 5 * time.Second
+)
+
+var errInotifyUnsupported = errors.New("inotify is not supported")
+
+type WatchOptions struct {
+	// Debounce is a quiet period after the last change before the command is restarted.
+	Debounce time.Duration
+	// Poll forces polling of files with the interval. If zero, inotify is used where available,
+	// otherwise files are polled every second.
+	Poll time.Duration
+	// StopTimeout is how long to wait for the command to exit after SIGTERM before killing it.
+	StopTimeout time.Duration
+}
+
+func (o WatchOptions) withDefaults() WatchOptions {
+	if o.Debounce <= 0 {
+		o.Debounce = defaultDebounce
+	}
+
+	if o.StopTimeout <= 0 {
+		o.StopTimeout = defaultStopTimeout
+	}
+
+	return o
+}
+
+// WatchCmd runs the command with the environment returned by load and restarts it with the reloaded
+// environment when files of paths (env directories or files) change. The command is not restarted
+// if the environment stays the same, unless it has exited. If reloading fails, the command keeps running.
+// WatchCmd returns when ctx is done or SIGINT, SIGTERM or SIGHUP is received, the signal is forwarded
+// to the command and the return code is the one of the command.
+func WatchCmd(ctx context.Context, cmd []string, paths []string, load func() (Environment, error),
+	opts WatchOptions,
+) (returnCode int) {
+	opts = opts.withDefaults()
+
+	env, err := load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return failedToRunCode
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	changes, err := watchPaths(ctx, paths, opts.Poll)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return failedToRunCode
+	}
+	changes = debounce(ctx, changes, opts.Debounce)
+
+	signals := make(chan os.Signal, len(forwardedSignals))
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
+
+	child, err := startChild(cmd, env)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return failedToRunCode
+	}
+	exited := child.done
+
+	for {
+		select {
+		case <-ctx.Done():
+			return child.stop(syscall.SIGTERM, opts.StopTimeout)
+		case sig := <-signals:
+			return child.stop(sig, opts.StopTimeout)
+		case <-exited:
+			exited = nil
+			fmt.Fprintf(os.Stderr, "command exited with code %d, waiting for changes\n", child.code)
+		case <-changes:
+			reloaded, err := load()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "cannot reload environment: %v\n", err)
+				continue
+			}
+
+			if child.running() && reflect.DeepEqual(env, reloaded) {
+				continue
+			}
+			env = reloaded
+
+			child.stop(syscall.SIGTERM, opts.StopTimeout)
+			if child, err = startChild(cmd, env); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return failedToRunCode
+			}
+			exited = child.done
+		}
+	}
+}
+
+// child is a running command, done is closed when it exits and code is set.
+type child struct {
+	cmd  *exec.Cmd
+	done chan struct{}
+	code int
+}
+
+func startChild(cmd []string, env Environment) (*child, error) {
+	command, err := startCmd(cmd, env)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &child{cmd: command, done: make(chan struct{})}
+	go func() {
+		c.code = exitCode(command.Wait())
+		close(c.done)
+	}()
+
+	return c, nil
+}
+
+func (c *child) running() bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// stop sends sig to the command and kills it if it doesn't exit in timeout.
+func (c *child) stop(sig os.Signal, timeout time.Duration) int {
+	if !c.running() {
+		return c.code
+	}
+
+	_ = c.cmd.Process.Signal(sig)
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-c.done:
+	case <-timer.C:
+		_ = c.cmd.Process.Kill()
+		<-c.done
+	}
+
+	return c.code
+}
+
+// debounce sends a value after in has been quiet for d since the last received value.
+func debounce(ctx context.Context, in <-chan struct{}, d time.Duration) <-chan struct{} {
+	out := make(chan struct{})
+
+	go func() {
+		timer := time.NewTimer(d)
+		timer.Stop()
+
+		var pending <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case _, ok := <-in:
+				if !ok {
+					return
+				}
+				// a fired but unreceived value would be taken for the end of the new period
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(d)
+				pending = timer.C
+			case <-pending:
+				pending = nil
+				select {
+				case out <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// watchPaths notifies about changes of env directories and files until ctx is done.
+// Files are watched through their directories, as editors often replace them by renaming.
+// Inotify is used unless poll is set or it is unavailable, then files are polled.
+func watchPaths(ctx context.Context, paths []string, poll time.Duration) (<-chan struct{}, error) {
+	targets := make([]watchTarget, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if info.IsDir() {
+			targets = append(targets, watchTarget{dir: path})
+		} else {
+			targets = append(targets, watchTarget{dir: filepath.Dir(path), name: filepath.Base(path)})
+		}
+	}
+
+	if poll <= 0 {
+		changes, err := watchInotify(ctx, targets)
+		if err == nil {
+			return changes, nil
+		}
+
+		if !errors.Is(err, errInotifyUnsupported) {
+			fmt.Fprintf(os.Stderr, "inotify is unavailable, polling files: %v\n", err)
+		}
+		poll = defaultPollInterval
+	}
+
+	return watchPolling(ctx, targets, poll), nil
+}
+
+// watchTarget is a directory or a file (name) in the directory.
+type watchTarget struct {
+	dir  string
+	name string
+}
+
+func (t watchTarget) matches(name string) bool {
+	return t.name == "" || t.name == name
+}
+
+// fileState is compared between polls, missing files are absent from snapshots.
+type fileState struct {
+	size    int64
+	mode    os.FileMode
+	modTime time.Time
+}
+
+func watchPolling(ctx context.Context, targets []watchTarget, interval time.Duration) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		last := snapshot(targets)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			current := snapshot(targets)
+			if reflect.DeepEqual(last, current) {
+				continue
+			}
+			last = current
+
+			select {
+			case changes <- struct{}{}:
+			default: // a change is pending already
+			}
+		}
+	}()
+
+	return changes
+}
+
+func snapshot(targets []watchTarget) map[string]fileState {
+	states := make(map[string]fileState)
+	for _, t := range targets {
+		if t.name != "" {
+			path := filepath.Join(t.dir, t.name)
+			if info, err := os.Stat(path); err == nil {
+				states[path] = fileState{size: info.Size(), mode: info.Mode(), modTime: info.ModTime()}
+			}
+			continue
+		}
+
+		entries, err := os.ReadDir(t.dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			if info, err := entry.Info(); err == nil {
+				states[filepath.Join(t.dir, entry.Name())] = fileState{
+					size: info.Size(), mode: info.Mode(), modTime: info.ModTime(),
+				}
+			}
+		}
+	}
+
+	return states
+}
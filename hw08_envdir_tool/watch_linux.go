@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MODIFY | syscall.IN_ATTRIB |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_CLOSE_WRITE | syscall.IN_DELETE_SELF
+
+// watchInotify watches directories of targets with inotify.
+func watchInotify(ctx context.Context, targets []watchTarget) (<-chan struct{}, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("inotify_init1: %w", err)
+	}
+
+	// the non-blocking descriptor is handled by the runtime poller, so Close interrupts Read
+	f := os.NewFile(uintptr(fd), "inotify")
+
+	byWatch := make(map[int32][]watchTarget)
+	for _, t := range targets {
+		wd, err := syscall.InotifyAddWatch(fd, t.dir, inotifyMask)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("inotify_add_watch %s: %w", t.dir, err)
+		}
+		byWatch[int32(wd)] = append(byWatch[int32(wd)], t)
+	}
+
+	go func() {
+		<-ctx.Done()
+		f.Close()
+	}()
+
+	changes := make(chan struct{}, 1)
+	go func() {
+		buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+		for {
+			n, err := f.Read(buf)
+			if err != nil {
+				return
+			}
+
+			if changed(buf[:n], byWatch) {
+				select {
+				case changes <- struct{}{}:
+				default: // a change is pending already
+				}
+			}
+		}
+	}()
+
+	return changes, nil
+}
+
+// changed reports whether the inotify events concern any of the targets.
+func changed(buf []byte, byWatch map[int32][]watchTarget) bool {
+	for len(buf) >= syscall.SizeofInotifyEvent {
+		event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[0]))
+		nameLen := int(event.Len)
+		nameBytes := buf[syscall.SizeofInotifyEvent : syscall.SizeofInotifyEvent+nameLen]
+		buf = buf[syscall.SizeofInotifyEvent+nameLen:]
+
+		name := string(nameBytes)
+		for i, c := range nameBytes {
+			if c == 0 {
+				name = string(nameBytes[:i])
+				break
+			}
+		}
+
+		for _, t := range byWatch[event.Wd] {
+			if event.Mask&syscall.IN_DELETE_SELF != 0 || t.matches(name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
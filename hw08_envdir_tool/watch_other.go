@@ -0,0 +1,10 @@
+//go:build !linux
+
+package main
+
+import "context"
+
+// watchInotify is available on Linux only, files are polled elsewhere.
+func watchInotify(context.Context, []watchTarget) (<-chan struct{}, error) {
+	return nil, errInotifyUnsupported
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebounce(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan struct{})
+	out := debounce(ctx, in, 50*time.Millisecond)
+
+	for i := 0; i < 5; i++ {
+		in <- struct{}{}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case <-out:
+	case <-time.After(time.Second):
+		t.Fatal("no value after a burst")
+	}
+
+	select {
+	case <-out:
+		t.Fatal("a burst must produce a single value")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWatchPaths(t *testing.T) {
+	modes := map[string]time.Duration{"inotify": 0, "polling": 10 * time.Millisecond}
+
+	for mode, poll := range modes {
+		poll := poll
+		t.Run(mode, func(t *testing.T) {
+			dir := envDir(t, map[string]string{"FOO": "foo"})
+			filesDir := envDir(t, map[string]string{"app.env": "BAR=bar", "other.txt": ""})
+			file := filepath.Join(filesDir, "app.env")
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			changes, err := watchPaths(ctx, []string{dir, file}, poll)
+			require.NoError(t, err)
+
+			requireChange := func(msg string, change func()) {
+				t.Helper()
+
+				// modification times of quick successive writes may be equal, so the size changes too
+				time.Sleep(20 * time.Millisecond)
+				drain(changes)
+				change()
+
+				select {
+				case <-changes:
+				case <-time.After(2 * time.Second):
+					t.Fatal(msg)
+				}
+			}
+
+			requireChange("modified file of directory", func() { writeFile(t, filepath.Join(dir, "FOO"), "foo2") })
+			requireChange("new file of directory", func() { writeFile(t, filepath.Join(dir, "NEW"), "new") })
+			requireChange("removed file of directory", func() { require.NoError(t, os.Remove(filepath.Join(dir, "NEW"))) })
+			requireChange("modified file", func() { writeFile(t, file, "BAR=bar2") })
+			requireChange("replaced file", func() {
+				writeFile(t, file+".tmp", "BAR=replaced")
+				require.NoError(t, os.Rename(file+".tmp", file))
+			})
+
+			time.Sleep(50 * time.Millisecond)
+			drain(changes)
+			writeFile(t, filepath.Join(filesDir, "other.txt"), "unrelated")
+
+			select {
+			case <-changes:
+				t.Fatal("other files of the directory of a watched file are ignored")
+			case <-time.After(100 * time.Millisecond):
+			}
+		})
+	}
+
+	_, err := watchPaths(context.Background(), []string{"/nonexistent"}, 0)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestWatchCmd(t *testing.T) {
+	dir := envDir(t, map[string]string{"VALUE": "1"})
+	out := filepath.Join(t.TempDir(), "out")
+	cmd := []string{"/bin/sh", "-c", `echo "$VALUE" >> "$0"; exec sleep 60`, out}
+
+	load := func() (Environment, error) {
+		return ReadDir(dir)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	code := make(chan int, 1)
+	go func() {
+		code <- WatchCmd(ctx, cmd, []string{dir}, load, WatchOptions{Debounce: 20 * time.Millisecond})
+	}()
+
+	requireOutput := func(expected string) {
+		t.Helper()
+		require.Eventually(t, func() bool {
+			data, _ := os.ReadFile(out)
+			return string(data) == expected
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	requireOutput("1\n")
+
+	writeFile(t, filepath.Join(dir, "VALUE"), "2")
+	requireOutput("1\n2\n")
+
+	// the same environment doesn't restart the command
+	writeFile(t, filepath.Join(dir, "VALUE"), "2  ")
+	time.Sleep(200 * time.Millisecond)
+	requireOutput("1\n2\n")
+
+	// a broken environment keeps the command running
+	writeFile(t, filepath.Join(dir, "BROKEN=NAME"), "x")
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, os.Remove(filepath.Join(dir, "BROKEN=NAME")))
+	writeFile(t, filepath.Join(dir, "VALUE"), "3")
+	requireOutput("1\n2\n3\n")
+
+	cancel()
+
+	select {
+	case c := <-code:
+		require.Equal(t, 128+int(syscall.SIGTERM), c)
+	case <-time.After(5 * time.Second):
+		t.Fatal("WatchCmd has not returned")
+	}
+}
+
+func TestWatchCmdExited(t *testing.T) {
+	dir := envDir(t, map[string]string{"CODE": "3"})
+	load := func() (Environment, error) {
+		return ReadDir(dir)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	code := make(chan int, 1)
+	go func() {
+		code <- WatchCmd(ctx, []string{"/bin/sh", "-c", `exit "$CODE"`}, []string{dir}, load, WatchOptions{
+			Debounce: 20 * time.Millisecond,
+			Poll:     10 * time.Millisecond,
+		})
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	select {
+	case c := <-code:
+		require.Equal(t, 3, c, "the exit code of the last command is returned")
+	case <-time.After(5 * time.Second):
+		t.Fatal("WatchCmd has not returned")
+	}
+
+	require.Equal(t, failedToRunCode, WatchCmd(context.Background(), []string{"true"}, []string{dir},
+		func() (Environment, error) { return nil, ErrInvalidName }, WatchOptions{}))
+}
+
+func TestChildStopTimeout(t *testing.T) {
+	c, err := startChild([]string{"/bin/sh", "-c", `trap "" TERM; while :; do sleep 0.01; done`}, nil)
+	require.NoError(t, err)
+
+	time.Sleep(50 * time.Millisecond)
+	start := time.Now()
+	require.Equal(t, 128+int(syscall.SIGKILL), c.stop(syscall.SIGTERM, 100*time.Millisecond))
+	require.Less(t, time.Since(start), 2*time.Second)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func drain(ch <-chan struct{}) {
+	for {
+		select {
+		case <-ch:
+		default:
+			return
+		}
+	}
+}
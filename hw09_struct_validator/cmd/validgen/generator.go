@@ -0,0 +1,898 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	validatorPath = "github.com/fixme_my_friend/hw09_struct_validator"
+	validatorName = "hw09structvalidator"
+)
+
+// generator writes Validate methods of struct types. Every struct type gets a validgen method
+// doing the work of the reflection walker for its plan: fields are validated in the same order,
+// errors are made with the same formats.
+type generator struct {
+	pkg *types.Package
+	// prefix qualifies identifiers of the validator package.
+	prefix  string
+	imports map[string]bool
+	helpers map[string]bool
+
+	vars    bytes.Buffer
+	varIDs  map[string]string
+	methods bytes.Buffer
+	body    bytes.Buffer
+	n       int
+
+	queue  []*types.Named
+	queued map[*types.Named]bool
+}
+
+func generate(pkg *types.Package, names []string) ([]byte, error) {
+	g := &generator{
+		pkg:     pkg,
+		imports: map[string]bool{"strconv": true, "strings": true},
+		helpers: make(map[string]bool),
+		varIDs:  make(map[string]string),
+		queued:  make(map[*types.Named]bool),
+	}
+	// loadPackage doesn't know the import path, so the validator package is recognized by its name
+	if pkg.Name() != validatorName {
+		g.prefix = validatorName + "."
+		g.imports[validatorPath] = true
+	}
+
+	for _, name := range names {
+		named, err := g.lookup(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+
+		fmt.Fprintf(&g.methods, `
+// Validate validates x like %[1]sValidate(x) does, without reflection.
+func (x %[2]s) Validate() error {
+	w := validgenWalker{path: make([]validgenElem, 0, 8)}
+	x.validgen(&w)
+
+	return w.result()
+}
+`, g.prefix, named.Obj().Name())
+		g.enqueue(named)
+	}
+
+	for i := 0; i < len(g.queue); i++ {
+		if err := g.structMethod(g.queue[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return g.source(names)
+}
+
+func (g *generator) lookup(name string) (*types.Named, error) {
+	obj, ok := g.pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not found in package %s", name, g.pkg.Name())
+	}
+
+	named, ok := types.Unalias(obj.Type()).(*types.Named)
+	if !ok || !isStruct(named) {
+		return nil, fmt.Errorf("type %s is not a struct", name)
+	}
+
+	return named, nil
+}
+
+func (g *generator) enqueue(named *types.Named) {
+	if !g.queued[named] {
+		g.queued[named] = true
+		g.queue = append(g.queue, named)
+	}
+}
+
+func (g *generator) source(names []string) ([]byte, error) {
+	var src bytes.Buffer
+
+	fmt.Fprintf(&src, "// Code generated by validgen -type %s; DO NOT EDIT.\n\npackage %s\n\nimport (\n",
+		strings.Join(names, ","), g.pkg.Name())
+
+	imports := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+
+	for _, path := range imports {
+		fmt.Fprintf(&src, "%q\n", path)
+	}
+	src.WriteString(")\n")
+
+	if g.vars.Len() > 0 {
+		fmt.Fprintf(&src, "\nvar (\n%s)\n", g.vars.Bytes())
+	}
+
+	src.Write(g.methods.Bytes())
+	src.Write(g.body.Bytes())
+
+	replacer := strings.NewReplacer("$v.", g.prefix)
+	src.WriteString(replacer.Replace(walkerHelper))
+	for _, name := range []string{"sortedKeys", "sortedOrderedKeys", "isEmail", "isURL", "uuid", "parseTime"} {
+		if g.helpers[name] {
+			src.WriteString(helpers[name])
+		}
+	}
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated code is invalid: %w\n%s", err, src.Bytes())
+	}
+
+	return formatted, nil
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.body, format+"\n", args...)
+}
+
+// tmp returns a new name of a local variable.
+func (g *generator) tmp(prefix string) string {
+	g.n++
+	return prefix + strconv.Itoa(g.n)
+}
+
+func (g *generator) use(path string) {
+	g.imports[path] = true
+}
+
+func (g *generator) helper(name string, imports ...string) {
+	g.helpers[name] = true
+	for _, path := range imports {
+		g.use(path)
+	}
+}
+
+// typeString writes a type as it is written in the package, importing packages of the type.
+func (g *generator) typeString(t types.Type) string {
+	return types.TypeString(t, func(p *types.Package) string {
+		if p == g.pkg {
+			return ""
+		}
+		g.use(p.Path())
+		return p.Name()
+	})
+}
+
+// sentinel returns an expression of a validation error of the validator package.
+func (g *generator) sentinel(name string) string {
+	return g.prefix + name
+}
+
+func (g *generator) fail(r rule, errExpr string, args ...interface{}) {
+	g.use("fmt")
+	g.printf("w.fail(%q, %q, %s)", r.name, r.arg, fmt.Sprintf(errExpr, args...))
+}
+
+func (g *generator) structMethod(named *types.Named) error {
+	st := named.Underlying().(*types.Struct)
+
+	g.printf("\nfunc (x *%s) validgen(w *validgenWalker) {", named.Obj().Name())
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		tag := reflect.StructTag(st.Tag(i)).Get("validate")
+		if tag == "" || !f.Exported() {
+			continue
+		}
+
+		if err := g.field(named, f, tag); err != nil {
+			return fmt.Errorf("field %s.%s: %w", named.Obj().Name(), f.Name(), err)
+		}
+	}
+	g.printf("}")
+
+	return nil
+}
+
+// field writes validation of the field like walker.validateField.
+func (g *generator) field(parent *types.Named, f *types.Var, tag string) error {
+	rules, err := parseRules(tag)
+	if err != nil {
+		return err
+	}
+
+	t := f.Type()
+	base := scalarType(t)
+	switch {
+	case rules.nested && !isStruct(base):
+		return fmt.Errorf("nested for %s", t)
+	case isStruct(base) && len(rules.checks) > 0:
+		return fmt.Errorf("rule %s for structs is unknown", rules.checks[0].name)
+	case isStruct(base) && !rules.nested:
+		return fmt.Errorf("%s, structs require nested", t)
+	}
+
+	expr := "x." + f.Name()
+	g.printf("w.path = append(w.path, validgenElem{field: %q})", f.Name())
+
+	branches := 0
+	if rules.required || len(rules.requiredIf) > 0 {
+		empty := g.isEmpty(expr, t)
+		if rules.required {
+			g.printf("if %s {", empty)
+			g.fail(rule{name: "required"}, "%s", g.sentinel("ErrRequired"))
+			branches++
+		}
+
+		for _, r := range rules.requiredIf {
+			if rules.required {
+				break
+			}
+
+			cond, err := g.condition(parent, r)
+			if err != nil {
+				return fmt.Errorf("%s: %w", r.name, err)
+			}
+
+			if branches > 0 {
+				g.printf("} else if %s && %s {", empty, cond)
+			} else {
+				g.printf("if %s && %s {", empty, cond)
+			}
+			name, value, _ := strings.Cut(r.arg, " ")
+			g.fail(r, "fmt.Errorf(\"%%w if %%s is %%s\", %s, %q, %q)", g.sentinel("ErrRequired"), name, value)
+			branches++
+		}
+
+		g.printf("} else {")
+	}
+
+	if err := g.fieldChecks(parent, expr, t, rules); err != nil {
+		return err
+	}
+
+	if rules.nested || len(rules.checks) > 0 {
+		if err := g.value(expr, t, rules); err != nil {
+			return err
+		}
+	}
+
+	if branches > 0 {
+		g.printf("}")
+	}
+	g.printf("w.path = w.path[:len(w.path)-1]")
+
+	return nil
+}
+
+// fieldChecks writes min_len, max_len and cross-field checks of the field under pointers.
+func (g *generator) fieldChecks(parent *types.Named, expr string, t types.Type, rules *fieldRules) error {
+	if len(rules.field) == 0 && len(rules.cross) == 0 {
+		return nil
+	}
+
+	conds, v, vt := deref(expr, t)
+	if len(conds) > 0 {
+		g.printf("if %s {", strings.Join(conds, " && "))
+	}
+
+	for _, r := range rules.field {
+		if err := g.lenCheck(r, v, vt); err != nil {
+			return fmt.Errorf("%s: %w", r.name, err)
+		}
+	}
+
+	for _, r := range rules.cross {
+		if err := g.crossCheck(parent, r, v, vt); err != nil {
+			return fmt.Errorf("%s: %w", r.name, err)
+		}
+	}
+
+	if len(conds) > 0 {
+		g.printf("}")
+	}
+
+	return nil
+}
+
+func (g *generator) lenCheck(r rule, v string, t types.Type) error {
+	var length string
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		if u.Info()&types.IsString == 0 {
+			return fmt.Errorf("unsupported type %s", t)
+		}
+		g.use("unicode/utf8")
+		length = fmt.Sprintf("utf8.RuneCountInString(string(%s))", v)
+	case *types.Slice, *types.Array, *types.Map:
+		length = fmt.Sprintf("len(%s)", v)
+	default:
+		return fmt.Errorf("unsupported type %s", t)
+	}
+
+	n, err := strconv.Atoi(r.arg)
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid length %q", r.arg)
+	}
+
+	op, errName := "<", "ErrMinLen"
+	if r.name == "max_len" {
+		op, errName = ">", "ErrMaxLen"
+	}
+
+	g.printf("if l := %s; l %s %d {", length, op, n)
+	g.fail(r, "fmt.Errorf(\"%%w: %%d %s %%d\", %s, l, %d)", op, g.sentinel(errName), n)
+	g.printf("}")
+
+	return nil
+}
+
+// sibling finds the field of the parent struct named in a tag.
+func (g *generator) sibling(parent *types.Named, name string) (*types.Var, error) {
+	obj, _, indirect := types.LookupFieldOrMethod(parent, false, g.pkg, name)
+	f, ok := obj.(*types.Var)
+	if !ok || !f.IsField() || !f.Exported() {
+		return nil, fmt.Errorf("no field %q in %s", name, parent.Obj().Name())
+	}
+	if indirect {
+		return nil, fmt.Errorf("field %s is promoted through a pointer", name)
+	}
+
+	return f, nil
+}
+
+var crossRules = map[string]struct {
+	sentinel string
+	op       string
+}{
+	"eqfield":  {"ErrEqField", "=="},
+	"nefield":  {"ErrNeField", "!="},
+	"gtfield":  {"ErrGt", ">"},
+	"gtefield": {"ErrGte", ">="},
+	"ltfield":  {"ErrLt", "<"},
+	"ltefield": {"ErrLte", "<="},
+}
+
+func (g *generator) crossCheck(parent *types.Named, r rule, v string, t types.Type) error {
+	f, err := g.sibling(parent, r.arg)
+	if err != nil {
+		return err
+	}
+
+	conds, other, ot := deref("x."+f.Name(), f.Type())
+	class := kindClass(t)
+	switch {
+	case class == "":
+		return fmt.Errorf("unsupported type %s", t)
+	case kindClass(ot) != class:
+		return fmt.Errorf("%s can't be compared with field %s of type %s", t, r.arg, ot)
+	case class == "bool" && r.name != "eqfield" && r.name != "nefield":
+		return fmt.Errorf("rule %s for bools is unknown", r.name)
+	}
+
+	var ok string
+	switch class {
+	case "bool":
+		ok = fmt.Sprintf("bool(%s) %s bool(%s)", v, crossRules[r.name].op, other)
+	case "time":
+		ok = fmt.Sprintf("%s.Compare(%s) %s 0", v, other, crossRules[r.name].op)
+	default:
+		g.use("cmp")
+		conv := map[string]string{"string": "string", "int": "int64", "uint": "uint64", "float": "float64"}[class]
+		ok = fmt.Sprintf("cmp.Compare(%[1]s(%[2]s), %[1]s(%[3]s)) %[4]s 0", conv, v, other, crossRules[r.name].op)
+	}
+
+	// values are not in the message, the fields may be secret
+	conds = append(conds, "!("+ok+")")
+	g.printf("if %s {", strings.Join(conds, " && "))
+	g.fail(r, "fmt.Errorf(\"%%w %%s\", %s, %q)", g.sentinel(crossRules[r.name].sentinel), r.arg)
+	g.printf("}")
+
+	return nil
+}
+
+// condition returns an expression of the required_if condition.
+func (g *generator) condition(parent *types.Named, r rule) (string, error) {
+	name, value, ok := strings.Cut(r.arg, " ")
+	if !ok {
+		return "", fmt.Errorf("%q, expected field and value", r.arg)
+	}
+
+	f, err := g.sibling(parent, name)
+	if err != nil {
+		return "", err
+	}
+
+	conds, v, t := deref("x."+f.Name(), f.Type())
+
+	var match string
+	switch kindClass(t) {
+	case "string":
+		match = fmt.Sprintf("string(%s) == %q", v, value)
+	case "int":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid number %q", value)
+		}
+		match = fmt.Sprintf("int64(%s) == %d", v, n)
+	case "uint":
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid number %q", value)
+		}
+		match = fmt.Sprintf("uint64(%s) == %d", v, n)
+	case "float":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid number %q", value)
+		}
+		match = fmt.Sprintf("float64(%s) == %s", v, g.float(f))
+	case "bool":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid bool %q", value)
+		}
+		match = fmt.Sprintf("bool(%s) == %t", v, b)
+	default:
+		return "", fmt.Errorf("unsupported type %s", t)
+	}
+
+	return strings.Join(append(conds, match), " && "), nil
+}
+
+// value writes validation of a value like walker.validateValue: rules are applied to elements
+// of containers and pointed values, nested structs are validated with their validgen methods.
+func (g *generator) value(expr string, t types.Type, rules *fieldRules) error {
+	t = types.Unalias(t)
+	if isTime(t) {
+		return g.checks(expr, t, rules.checks)
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		p := g.tmp("p")
+		g.printf("if %[1]s := %[2]s; %[1]s != nil {", p, expr)
+		if rules.nested {
+			g.printf("if w.enter(%s) {", p)
+			if err := g.value("(*"+p+")", u.Elem(), rules); err != nil {
+				return err
+			}
+			g.printf("w.leave(%s)", p)
+			g.printf("}")
+		} else if err := g.value("(*"+p+")", u.Elem(), rules); err != nil {
+			return err
+		}
+		g.printf("}")
+	case *types.Slice:
+		return g.elements(expr, u.Elem(), rules)
+	case *types.Array:
+		return g.elements(expr, u.Elem(), rules)
+	case *types.Map:
+		// keys are ordered like sortedKeys of the validator does
+		sortedKeys := "validgenSortedKeys"
+		if b, ok := u.Key().Underlying().(*types.Basic); ok && b.Info()&types.IsOrdered != 0 {
+			g.helper("sortedOrderedKeys", "cmp", "fmt", "sort")
+			sortedKeys = "validgenSortedOrderedKeys"
+		} else {
+			g.helper("sortedKeys", "fmt", "sort")
+		}
+		keys, names, i, k, v := g.tmp("keys"), g.tmp("names"), g.tmp("i"), g.tmp("k"), g.tmp("v")
+		g.printf("%s, %s := %s(%s)", keys, names, sortedKeys, expr)
+		g.printf("for %s, %s := range %s {", i, k, keys)
+		g.printf("w.path = append(w.path, validgenElem{key: %s[%s], isKey: true})", names, i)
+		g.printf("%s := %s[%s]", v, expr, k)
+		if err := g.value(v, u.Elem(), rules); err != nil {
+			return err
+		}
+		g.printf("w.path = w.path[:len(w.path)-1]")
+		g.printf("}")
+	case *types.Struct:
+		if !rules.nested {
+			return nil
+		}
+		named, ok := t.(*types.Named)
+		if !ok || named.Obj().Pkg() != g.pkg || named.TypeArgs().Len() > 0 {
+			return fmt.Errorf("nested %s must be a non-generic struct type declared in the package", t)
+		}
+		g.enqueue(named)
+		g.printf("%s.validgen(w)", expr)
+	case *types.Basic:
+		return g.checks(expr, t, rules.checks)
+	default:
+		return fmt.Errorf("unsupported type %s", t)
+	}
+
+	return nil
+}
+
+func (g *generator) elements(expr string, elem types.Type, rules *fieldRules) error {
+	i := g.tmp("i")
+	g.printf("for %s := range %s {", i, expr)
+	g.printf("w.path = append(w.path, validgenElem{index: %s})", i)
+	if err := g.value(expr+"["+i+"]", elem, rules); err != nil {
+		return err
+	}
+	g.printf("w.path = w.path[:len(w.path)-1]")
+	g.printf("}")
+
+	return nil
+}
+
+// checks writes scalar checks of a value, like checks made by newCheck.
+func (g *generator) checks(expr string, t types.Type, rules []rule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	var (
+		class = kindClass(t)
+		v     string
+		check func(r rule, v string) error
+	)
+
+	switch class {
+	case "string":
+		v, check = g.tmp("s"), g.stringCheck
+		g.printf("%s := string(%s)", v, expr)
+	case "int":
+		v, check = g.tmp("n"), g.intCheck
+		g.printf("%s := int64(%s)", v, expr)
+	case "float":
+		v, check = g.tmp("f"), g.floatCheck
+		g.printf("%s := float64(%s)", v, expr)
+	case "time":
+		v, check = g.tmp("t"), g.timeCheck
+		g.printf("%s := %s", v, expr)
+	default:
+		return fmt.Errorf("unsupported type %s", t)
+	}
+
+	for _, r := range rules {
+		if err := check(r, v); err != nil {
+			return fmt.Errorf("%s: %w", r.name, err)
+		}
+	}
+
+	return nil
+}
+
+// failValue writes a failure with the message "value err arg" like stringCheck does.
+func (g *generator) failValue(r rule, v, errName, arg string) {
+	if arg == "" {
+		g.fail(r, "fmt.Errorf(\"%%q %%w\", %s, %s)", v, g.sentinel(errName))
+		return
+	}
+	g.fail(r, "fmt.Errorf(\"%%q %%w %%s\", %s, %s, %q)", v, g.sentinel(errName), arg)
+}
+
+func (g *generator) stringCheck(r rule, s string) error {
+	switch r.name {
+	case "len":
+		n, err := strconv.Atoi(r.arg)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid length %q", r.arg)
+		}
+		g.use("unicode/utf8")
+		g.printf("if l := utf8.RuneCountInString(%s); l != %d {", s, n)
+		g.fail(r, "fmt.Errorf(\"%%w: expected %%d, got %%d\", %s, %d, l)", g.sentinel("ErrLen"), n)
+	case "regexp":
+		if _, err := regexp.Compile(r.arg); err != nil {
+			return err
+		}
+		re := g.variable("regexp "+r.arg, "validgenRegexp", fmt.Sprintf("regexp.MustCompile(%q)", r.arg))
+		g.use("regexp")
+		g.printf("if !%s.MatchString(%s) {", re, s)
+		g.failValue(r, s, "ErrRegexp", r.arg)
+	case "in":
+		g.printf("switch %s {", s)
+		g.printf("case %s:", strings.Join(quoteAll(unique(strings.Split(r.arg, ","))), ", "))
+		g.printf("default:")
+		g.failValue(r, s, "ErrIn", "{"+r.arg+"}")
+	case "prefix", "suffix", "contains":
+		fn := map[string]string{"prefix": "HasPrefix", "suffix": "HasSuffix", "contains": "Contains"}[r.name]
+		errName := map[string]string{"prefix": "ErrPrefix", "suffix": "ErrSuffix", "contains": "ErrContains"}[r.name]
+		g.printf("if !strings.%s(%s, %q) {", fn, s, r.arg)
+		g.failValue(r, s, errName, strconv.Quote(r.arg))
+	case "email":
+		g.helper("isEmail", "net/mail")
+		g.printf("if !validgenIsEmail(%s) {", s)
+		g.failValue(r, s, "ErrEmail", "")
+	case "uuid":
+		g.helper("uuid", "regexp")
+		g.printf("if !validgenUUID.MatchString(%s) {", s)
+		g.failValue(r, s, "ErrUUID", "")
+	case "url":
+		g.helper("isURL", "net/url")
+		g.printf("if !validgenIsURL(%s) {", s)
+		g.failValue(r, s, "ErrURL", "")
+	case "ip":
+		g.use("net")
+		g.printf("if net.ParseIP(%s) == nil {", s)
+		g.failValue(r, s, "ErrIP", "")
+	default:
+		return fmt.Errorf("rule %s for strings is unknown", r.name)
+	}
+	g.printf("}")
+
+	return nil
+}
+
+func (g *generator) intCheck(r rule, n string) error {
+	switch r.name {
+	case "min", "max":
+		limit, err := strconv.ParseInt(r.arg, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", r.arg)
+		}
+		op, errName := "<", "ErrMin"
+		if r.name == "max" {
+			op, errName = ">", "ErrMax"
+		}
+		g.printf("if %s %s %d {", n, op, limit)
+		g.fail(r, "fmt.Errorf(\"%%d %%w %%d\", %s, %s, int64(%d))", n, g.sentinel(errName), limit)
+	case "in", "oneof":
+		values := strings.Split(r.arg, ",")
+		if r.name == "oneof" {
+			values = strings.Fields(r.arg)
+		}
+
+		cases := make([]string, 0, len(values))
+		for _, s := range values {
+			limit, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid number %q", s)
+			}
+			cases = append(cases, strconv.FormatInt(limit, 10))
+		}
+
+		g.printf("switch %s {", n)
+		g.printf("case %s:", strings.Join(unique(cases), ", "))
+		g.printf("default:")
+		g.fail(r, "fmt.Errorf(\"%%d %%w {%%s}\", %s, %s, %q)", n, g.sentinel("ErrIn"), r.arg)
+	default:
+		return fmt.Errorf("rule %s for integers is unknown", r.name)
+	}
+	g.printf("}")
+
+	return nil
+}
+
+func (g *generator) floatCheck(r rule, f string) error {
+	errNames := map[string]string{"gt": "ErrGt", "gte": "ErrGte", "lt": "ErrLt", "lte": "ErrLte"}
+	ops := map[string]string{"gt": ">", "gte": ">=", "lt": "<", "lte": "<="}
+	if errNames[r.name] == "" {
+		return fmt.Errorf("rule %s for floats is unknown", r.name)
+	}
+
+	limit, err := strconv.ParseFloat(r.arg, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q", r.arg)
+	}
+
+	lit := g.float(limit)
+	g.printf("if !(%s %s %s) {", f, ops[r.name], lit)
+	g.fail(r, "fmt.Errorf(\"%%g %%w %%g\", %s, %s, %s)", f, g.sentinel(errNames[r.name]), lit)
+	g.printf("}")
+
+	return nil
+}
+
+// timeLayouts are accepted in arguments of before and after, like in the validator.
+var timeLayouts = []string{time.RFC3339, "2006-01-02"}
+
+func (g *generator) timeCheck(r rule, t string) error {
+	method, errName := "Before", "ErrBefore"
+	switch r.name {
+	case "before":
+	case "after":
+		method, errName = "After", "ErrAfter"
+	default:
+		return fmt.Errorf("rule %s for time is unknown", r.name)
+	}
+
+	layout := ""
+	for _, l := range timeLayouts {
+		if _, err := time.Parse(l, r.arg); err == nil {
+			layout = l
+			break
+		}
+	}
+	if layout == "" {
+		return fmt.Errorf("invalid time %q, expected RFC 3339 or 2006-01-02", r.arg)
+	}
+
+	g.helper("parseTime", "time")
+	limit := g.variable("time "+r.arg, "validgenTime", fmt.Sprintf("validgenParseTime(%q, %q)", layout, r.arg))
+	g.printf("if !%s.%s(%s) {", t, method, limit)
+	g.fail(r, "fmt.Errorf(\"%%s %%w %%s\", %s.Format(time.RFC3339), %s, %s.Format(time.RFC3339))",
+		t, g.sentinel(errName), limit)
+	g.printf("}")
+
+	return nil
+}
+
+// variable declares a package variable once for the key and returns its name.
+func (g *generator) variable(key, prefix, value string) string {
+	if name, ok := g.varIDs[key]; ok {
+		return name
+	}
+
+	name := prefix + strconv.Itoa(len(g.varIDs))
+	g.varIDs[key] = name
+	fmt.Fprintf(&g.vars, "%s = %s\n", name, value)
+
+	return name
+}
+
+// float returns a float64 expression of the number.
+func (g *generator) float(f float64) string {
+	switch {
+	case math.IsInf(f, 0) || math.IsNaN(f):
+		g.use("math")
+		if math.IsNaN(f) {
+			return "math.NaN()"
+		}
+		return fmt.Sprintf("math.Inf(%d)", int(math.Copysign(1, f)))
+	default:
+		return "float64(" + strconv.FormatFloat(f, 'g', -1, 64) + ")"
+	}
+}
+
+// isEmpty returns an expression reporting whether the value is empty like isEmpty of the validator:
+// a zero value, an empty slice or an empty map.
+func (g *generator) isEmpty(expr string, t types.Type) string {
+	switch u := t.Underlying().(type) {
+	case *types.Slice, *types.Map:
+		return fmt.Sprintf("len(%s) == 0", expr)
+	case *types.Pointer, *types.Interface, *types.Chan, *types.Signature:
+		return expr + " == nil"
+	case *types.Basic:
+		info := u.Info()
+		switch {
+		case info&types.IsString != 0:
+			return expr + ` == ""`
+		case info&types.IsBoolean != 0:
+			return "!" + expr
+		case u.Kind() == types.Float32:
+			g.use("math")
+			return fmt.Sprintf("math.Float32bits(float32(%s)) == 0", expr)
+		case u.Kind() == types.Float64 || u.Kind() == types.UntypedFloat:
+			g.use("math")
+			return fmt.Sprintf("math.Float64bits(float64(%s)) == 0", expr)
+		case info&types.IsInteger != 0:
+			return expr + " == 0"
+		case u.Kind() == types.UnsafePointer:
+			return expr + " == nil"
+		}
+	default:
+		if exactlyComparable(t) {
+			return fmt.Sprintf("%s == (%s{})", expr, g.typeString(t))
+		}
+	}
+
+	// the rest, like structs with floats, is compared by bits as reflect.Value.IsZero does
+	g.use("reflect")
+	return fmt.Sprintf("reflect.ValueOf(&%s).Elem().IsZero()", expr)
+}
+
+// exactlyComparable reports whether == of a struct or an array to its zero value works like IsZero:
+// there are no floats (-0 == 0), complex numbers or interfaces (== panics on incomparable values).
+func exactlyComparable(t types.Type) bool {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		return u.Info()&(types.IsFloat|types.IsComplex) == 0
+	case *types.Pointer, *types.Chan:
+		return true
+	case *types.Array:
+		return exactlyComparable(u.Elem())
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			if !exactlyComparable(u.Field(i).Type()) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// deref returns conditions of non-nil pointers, an expression and a type of the value under pointers.
+func deref(expr string, t types.Type) ([]string, string, types.Type) {
+	var conds []string
+	for {
+		p, ok := t.Underlying().(*types.Pointer)
+		if !ok {
+			return conds, expr, types.Unalias(t)
+		}
+		conds = append(conds, expr+" != nil")
+		expr, t = "(*"+expr+")", p.Elem()
+	}
+}
+
+// scalarType returns the type under pointers, slices, arrays and maps.
+func scalarType(t types.Type) types.Type {
+	for {
+		switch u := t.Underlying().(type) {
+		case *types.Pointer:
+			t = u.Elem()
+		case *types.Slice:
+			t = u.Elem()
+		case *types.Array:
+			t = u.Elem()
+		case *types.Map:
+			t = u.Elem()
+		default:
+			return types.Unalias(t)
+		}
+	}
+}
+
+func isTime(t types.Type) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "time" && named.Obj().Name() == "Time"
+}
+
+// isStruct reports whether t is a struct validated with nested, time.Time is a scalar.
+func isStruct(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Struct)
+	return ok && !isTime(t)
+}
+
+// kindClass groups types like kindClass of the validator, an empty string for other types.
+func kindClass(t types.Type) string {
+	if isTime(t) {
+		return "time"
+	}
+
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return ""
+	}
+
+	switch info := b.Info(); {
+	case info&types.IsString != 0:
+		return "string"
+	case info&types.IsUnsigned != 0 && b.Kind() != types.Uintptr:
+		return "uint"
+	case info&types.IsInteger != 0 && info&types.IsUnsigned == 0:
+		return "int"
+	case info&types.IsFloat != 0:
+		return "float"
+	case info&types.IsBoolean != 0:
+		return "bool"
+	default:
+		return ""
+	}
+}
+
+func unique(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
+
+func quoteAll(values []string) []string {
+	result := make([]string, len(values))
+	for i, v := range values {
+		result[i] = strconv.Quote(v)
+	}
+
+	return result
+}
@@ -0,0 +1,135 @@
+package main
+
+// walkerHelper collects errors of generated methods like the walker of the validator.
+// $v. is replaced with the qualifier of the validator package.
+const walkerHelper = `
+// validgenElem is a field name, a slice index or a map key in a path to a value.
+type validgenElem struct {
+	field string
+	index int
+	key   string
+	isKey bool
+}
+
+// validgenWalker collects errors, the path to the current value is built only when an error is reported.
+type validgenWalker struct {
+	path     []validgenElem
+	errs     $v.ValidationErrors
+	visiting map[any]bool
+}
+
+func (w *validgenWalker) fail(rule, param string, err error) {
+	var sb strings.Builder
+	for _, e := range w.path {
+		switch {
+		case e.field != "":
+			if sb.Len() > 0 {
+				sb.WriteByte('.')
+			}
+			sb.WriteString(e.field)
+		case e.isKey:
+			sb.WriteString("[" + e.key + "]")
+		default:
+			sb.WriteString("[" + strconv.Itoa(e.index) + "]")
+		}
+	}
+
+	w.errs = append(w.errs, $v.ValidationError{Field: sb.String(), Rule: rule, Param: param, Err: err})
+}
+
+// enter marks a pointed struct as being validated, a pointer cycle is not followed again.
+func (w *validgenWalker) enter(p any) bool {
+	if w.visiting[p] {
+		return false
+	}
+
+	if w.visiting == nil {
+		w.visiting = make(map[any]bool)
+	}
+	w.visiting[p] = true
+
+	return true
+}
+
+func (w *validgenWalker) leave(p any) {
+	delete(w.visiting, p)
+}
+
+func (w *validgenWalker) result() error {
+	if len(w.errs) > 0 {
+		return w.errs
+	}
+
+	return nil
+}
+`
+
+// helpers are written to the output if generated code uses them.
+var helpers = map[string]string{
+	"sortedKeys": `
+// validgenSortedKeys returns keys of the map and their names in a stable order, keys are compared by their names.
+func validgenSortedKeys[M ~map[K]V, K comparable, V any](m M) ([]K, []string) {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+	})
+
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = fmt.Sprint(k)
+	}
+
+	return keys, names
+}
+`,
+	"sortedOrderedKeys": `
+// validgenSortedOrderedKeys returns keys of the map and their names, numbers and strings are compared as they are.
+func validgenSortedOrderedKeys[M ~map[K]V, K cmp.Ordered, V any](m M) ([]K, []string) {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = fmt.Sprint(k)
+	}
+
+	return keys, names
+}
+`,
+	"isEmail": `
+// validgenIsEmail accepts a bare address such as user@example.com.
+func validgenIsEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Name == "" && addr.Address == s
+}
+`,
+	"isURL": `
+// validgenIsURL accepts absolute URLs with a host.
+func validgenIsURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+`,
+	"uuid": `
+var validgenUUID = regexp.MustCompile(` + "`" +
+		`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$` + "`" + `)
+`,
+	"parseTime": `
+func validgenParseTime(layout, value string) time.Time {
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+`,
+}
@@ -0,0 +1,113 @@
+// Command validgen generates Validate methods of struct types from their validate tags.
+// The methods report the same errors as hw09structvalidator.Validate without reflection:
+//
+//	//go:generate go run github.com/fixme_my_friend/hw09_struct_validator/cmd/validgen -type User,Address
+//
+// Structs validated with nested rules must be declared in the same package, their methods are
+// generated too. Custom rules registered at run time are not supported. Helpers of the generated
+// code are put in the output file, so a package should have a single output file.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrNoTypes = errors.New("types are not specified")
+
+var typeNames, output string
+
+func init() {
+	flag.StringVar(&typeNames, "type", "", "comma separated names of struct types to generate Validate methods for")
+	flag.StringVar(&output, "output", "validate_gen.go",
+		"output file in the package directory; types of test files are available if it ends with _test.go")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage:\n  %s -type T1,T2 [flags] [package dir]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	if err := run(dir, typeNames, output); err != nil {
+		fmt.Fprintln(os.Stderr, "validgen:", err)
+		if errors.Is(err, ErrNoTypes) {
+			flag.Usage()
+		}
+		os.Exit(1)
+	}
+}
+
+func run(dir, typeNames, output string) error {
+	if typeNames == "" {
+		return ErrNoTypes
+	}
+
+	pkg, err := loadPackage(dir, output)
+	if err != nil {
+		return err
+	}
+
+	src, err := generate(pkg, strings.Split(typeNames, ","))
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, output), src, 0o644) //nolint:gosec
+}
+
+// loadPackage type checks Go files of the package in dir except the output file, so stale generated
+// code doesn't matter. Errors of type checking are ignored: a test file may use generated methods,
+// types of the fields are resolved anyway.
+func loadPackage(dir, output string) (*types.Package, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	tests := strings.HasSuffix(output, "_test.go")
+	fset := token.NewFileSet()
+
+	var files []*ast.File
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || name == filepath.Base(output) ||
+			(!tests && strings.HasSuffix(name, "_test.go")) {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+
+		// external test packages are not the package
+		if !strings.HasSuffix(f.Name.Name, "_test") {
+			files = append(files, f)
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil), Error: func(error) {}}
+	pkg, _ := conf.Check(files[0].Name.Name, fset, files, nil)
+
+	return pkg, nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGeneratedCodeIsUpToDate(t *testing.T) {
+	const (
+		dir    = "../.."
+		output = "validator_gen_test.go"
+	)
+
+	expected, err := os.ReadFile(filepath.Join(dir, output))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the command line is in the header of the file
+	header, _, _ := bytes.Cut(expected, []byte("\n"))
+	typeNames := strings.TrimSuffix(strings.TrimPrefix(string(header), "// Code generated by validgen -type "),
+		"; DO NOT EDIT.")
+
+	pkg, err := loadPackage(dir, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := generate(pkg, strings.Split(typeNames, ","))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(src, expected) {
+		t.Fatalf("%s is outdated, run go generate", output)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{
+			name:     "not a struct",
+			src:      "type T int",
+			expected: "type T is not a struct",
+		},
+		{
+			name:     "custom rule",
+			src:      "type T struct {\n INN string `validate:\"inn\"`\n}",
+			expected: `field T.INN: unknown rule "inn", custom rules are not supported`,
+		},
+		{
+			name:     "invalid argument",
+			src:      "type T struct {\n Name string `validate:\"len:x\"`\n}",
+			expected: `field T.Name: len: invalid length "x"`,
+		},
+		{
+			name:     "rule of another type",
+			src:      "type T struct {\n Age int `validate:\"email\"`\n}",
+			expected: "field T.Age: email: rule email for integers is unknown",
+		},
+		{
+			name:     "struct without nested",
+			src:      "type T struct {\n Inner I `validate:\"required\"`\n}\ntype I struct{}",
+			expected: "field T.Inner: p.I, structs require nested",
+		},
+		{
+			name:     "anonymous nested struct",
+			src:      "type T struct {\n Inner struct{ A int } `validate:\"nested\"`\n}",
+			expected: "must be a non-generic struct type declared in the package",
+		},
+		{
+			name:     "unknown sibling",
+			src:      "type T struct {\n Confirm string `validate:\"eqfield:Password\"`\n}",
+			expected: `field T.Confirm: eqfield: no field "Password" in T`,
+		},
+		{
+			name:     "incomparable sibling",
+			src:      "type T struct {\n End int `validate:\"gtfield:Start\"`\n Start string\n}",
+			expected: "field T.End: gtfield: int can't be compared with field Start of type string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "p.go"), []byte("package p\n\n"+tt.src+"\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			err := run(dir, "T", "validate_gen.go")
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Fatalf("expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a package other than the validator uses its exported identifiers
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/p\n\ngo 1.22\n\nrequire " + validatorPath + " v0.0.0\n\nreplace " +
+			validatorPath + " => " + root + "\n",
+		"p.go": `package p
+
+import "time"
+
+type T struct {
+	Name    string            ` + "`validate:\"len:2\"`" + `
+	Email   string            ` + "`validate:\"required|email\"`" + `
+	Scores  map[string]int    ` + "`validate:\"min:0\"`" + `
+	Offices map[string]*Inner ` + "`validate:\"nested\"`" + `
+	Start   time.Time         ` + "`validate:\"before:2030-01-01\"`" + `
+	End     *time.Time        ` + "`validate:\"gtfield:Start\"`" + `
+}
+
+type Inner struct {
+	ID string ` + "`validate:\"uuid\"`" + `
+}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := run(dir, "", "validate_gen.go"); err == nil {
+		t.Fatal("expected an error without types")
+	}
+
+	if err := run(dir, "T", "validate_gen.go"); err != nil {
+		t.Fatal(err)
+	}
+
+	generated, err := os.ReadFile(filepath.Join(dir, "validate_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{
+		"func (x T) Validate() error",
+		"func (x *Inner) validgen(w *validgenWalker)",
+	} {
+		if !bytes.Contains(generated, []byte(s)) {
+			t.Errorf("generated code doesn't contain %s", s)
+		}
+	}
+
+	// the generated code must compile against the validator
+	cmd := exec.Command("go", "vet", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go vet of generated code: %v\n%s", err, out)
+	}
+}
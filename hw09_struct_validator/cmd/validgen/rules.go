@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	hw09structvalidator "github.com/fixme_my_friend/hw09_struct_validator"
+)
+
+type rule struct {
+	name string
+	arg  string
+}
+
+// fieldRules are rules of a field grouped like the validator groups them.
+type fieldRules struct {
+	nested     bool
+	required   bool
+	field      []rule
+	checks     []rule
+	cross      []rule
+	requiredIf []rule
+}
+
+func parseRules(tag string) (*fieldRules, error) {
+	rules := &fieldRules{}
+
+	for _, r := range strings.Split(tag, "|") {
+		name, arg, hasArg := strings.Cut(r, ":")
+
+		// the rules are the rules of the validator, custom rules are registered at run time
+		builtin, requiresArg := hw09structvalidator.BuiltinRule(name)
+		if !builtin {
+			return nil, fmt.Errorf("unknown rule %q, custom rules are not supported", name)
+		}
+		if hasArg != requiresArg {
+			return nil, fmt.Errorf("invalid rule %q, expected rule or rule:argument", r)
+		}
+
+		switch name {
+		case "nested":
+			rules.nested = true
+		case "required":
+			rules.required = true
+		case "min_len", "max_len":
+			rules.field = append(rules.field, rule{name: name, arg: arg})
+		case "required_if":
+			rules.requiredIf = append(rules.requiredIf, rule{name: name, arg: arg})
+		default:
+			if _, ok := crossRules[name]; ok {
+				rules.cross = append(rules.cross, rule{name: name, arg: arg})
+				continue
+			}
+			rules.checks = append(rules.checks, rule{name: name, arg: arg})
+		}
+	}
+
+	return rules, nil
+}
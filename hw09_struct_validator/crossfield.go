@@ -0,0 +1,227 @@
+package hw09structvalidator
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrEqField = errors.New("must be equal to")
+	ErrNeField = errors.New("must not be equal to")
+)
+
+const requiredIfRule = "required_if"
+
+// crossCheck compares the field with a sibling field of the same struct, both are values under pointers.
+type crossCheck struct {
+	rule  string
+	param string
+	index []int
+	check func(v, other reflect.Value) error
+}
+
+// condition of required_if, it is met if the sibling field equals the value from the tag.
+type condition struct {
+	param string
+	index []int
+	match func(v reflect.Value) bool
+	err   error
+}
+
+// sibling returns the value of the field with the index under pointers,
+// an invalid value for nil pointers.
+func sibling(parent reflect.Value, index []int) reflect.Value {
+	v, err := parent.FieldByIndexErr(index)
+	if err != nil {
+		return reflect.Value{}
+	}
+
+	return indirect(v)
+}
+
+func (c *condition) met(parent reflect.Value) bool {
+	v := sibling(parent, c.index)
+	return v.IsValid() && c.match(v)
+}
+
+// lookupField finds the exported field of the struct type named in a tag.
+func lookupField(parent reflect.Type, name string) (reflect.StructField, error) {
+	field, ok := parent.FieldByName(name)
+	if !ok || !field.IsExported() {
+		return reflect.StructField{}, fmt.Errorf("%w: no field %q in %s", ErrInvalidTag, name, parent)
+	}
+
+	return field, nil
+}
+
+// newCrossCheck makes eqfield, nefield, gtfield, gtefield, ltfield and ltefield checks of a field of type t.
+// Both fields must be strings, integers, floats, bools or time.Time, possibly under pointers.
+// Bools can only be checked with eqfield and nefield.
+func newCrossCheck(name, arg string, t, parent reflect.Type) (crossCheck, error) {
+	field, err := lookupField(parent, arg)
+	if err != nil {
+		return crossCheck{}, err
+	}
+
+	t, other := derefType(t), derefType(field.Type)
+	class := kindClass(t)
+	if class == "" {
+		return crossCheck{}, fmt.Errorf("%w: %s", ErrUnsupportedType, t)
+	}
+	if kindClass(other) != class {
+		return crossCheck{}, fmt.Errorf("%w: %s can't be compared with field %s of type %s",
+			ErrInvalidTag, t, arg, other)
+	}
+
+	if class == "bool" && name != "eqfield" && name != "nefield" {
+		return crossCheck{}, fmt.Errorf("%w for bools", ErrUnknownRule)
+	}
+
+	var (
+		errRule error
+		ok      func(c int) bool
+	)
+
+	switch name {
+	case "eqfield":
+		errRule, ok = ErrEqField, func(c int) bool { return c == 0 }
+	case "nefield":
+		errRule, ok = ErrNeField, func(c int) bool { return c != 0 }
+	case "gtfield":
+		errRule, ok = ErrGt, func(c int) bool { return c > 0 }
+	case "gtefield":
+		errRule, ok = ErrGte, func(c int) bool { return c >= 0 }
+	case "ltfield":
+		errRule, ok = ErrLt, func(c int) bool { return c < 0 }
+	default:
+		errRule, ok = ErrLte, func(c int) bool { return c <= 0 }
+	}
+
+	compare := compareFunc(class)
+	c := func(v, other reflect.Value) error {
+		// values are not in the message, the fields may be secret
+		if !ok(compare(v, other)) {
+			return fmt.Errorf("%w %s", errRule, arg)
+		}
+		return nil
+	}
+
+	return crossCheck{rule: name, param: arg, index: field.Index, check: c}, nil
+}
+
+// newCondition parses the argument of required_if: a sibling field name and a value separated by a space.
+func newCondition(arg string, parent reflect.Type) (condition, error) {
+	name, value, ok := strings.Cut(arg, " ")
+	if !ok {
+		return condition{}, fmt.Errorf("%w: %q, expected field and value", ErrInvalidTag, arg)
+	}
+
+	field, err := lookupField(parent, name)
+	if err != nil {
+		return condition{}, err
+	}
+
+	match, err := newMatch(derefType(field.Type), value)
+	if err != nil {
+		return condition{}, fmt.Errorf("field %s: %w", name, err)
+	}
+
+	return condition{
+		param: arg,
+		index: field.Index,
+		match: match,
+		err:   fmt.Errorf("%w if %s is %s", ErrRequired, name, value),
+	}, nil
+}
+
+// newMatch returns a function reporting whether a value of type t equals s.
+func newMatch(t reflect.Type, s string) (func(v reflect.Value) bool, error) {
+	switch kindClass(t) {
+	case "string":
+		return func(v reflect.Value) bool { return v.String() == s }, nil
+	case "int":
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: number %q", ErrInvalidTag, s)
+		}
+		return func(v reflect.Value) bool { return v.Int() == n }, nil
+	case "uint":
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: number %q", ErrInvalidTag, s)
+		}
+		return func(v reflect.Value) bool { return v.Uint() == n }, nil
+	case "float":
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: number %q", ErrInvalidTag, s)
+		}
+		return func(v reflect.Value) bool { return v.Float() == f }, nil
+	case "bool":
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bool %q", ErrInvalidTag, s)
+		}
+		return func(v reflect.Value) bool { return v.Bool() == b }, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, t)
+	}
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+// kindClass groups types compared the same way, an empty string for types which can't be compared.
+func kindClass(t reflect.Type) string {
+	switch k := t.Kind(); {
+	case t == timeType:
+		return "time"
+	case k == reflect.String:
+		return "string"
+	case isInt(k):
+		return "int"
+	case k == reflect.Uint || k == reflect.Uint8 || k == reflect.Uint16 || k == reflect.Uint32 || k == reflect.Uint64:
+		return "uint"
+	case k == reflect.Float32 || k == reflect.Float64:
+		return "float"
+	case k == reflect.Bool:
+		return "bool"
+	default:
+		return ""
+	}
+}
+
+// compareFunc returns a function comparing values of the class like cmp.Compare.
+func compareFunc(class string) func(a, b reflect.Value) int {
+	switch class {
+	case "time":
+		return func(a, b reflect.Value) int {
+			return a.Interface().(time.Time).Compare(b.Interface().(time.Time))
+		}
+	case "string":
+		return func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) }
+	case "int":
+		return func(a, b reflect.Value) int { return cmp.Compare(a.Int(), b.Int()) }
+	case "uint":
+		return func(a, b reflect.Value) int { return cmp.Compare(a.Uint(), b.Uint()) }
+	case "float":
+		return func(a, b reflect.Value) int { return cmp.Compare(a.Float(), b.Float()) }
+	default:
+		return func(a, b reflect.Value) int {
+			if a.Bool() == b.Bool() {
+				return 0
+			}
+			return 1
+		}
+	}
+}
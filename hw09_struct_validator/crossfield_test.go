@@ -0,0 +1,139 @@
+package hw09structvalidator
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type (
+	Signup struct {
+		Role     string `validate:"in:user,admin"`
+		Password string `validate:"min_len:8"`
+		Confirm  string `validate:"eqfield:Password"`
+		Login    string `validate:"required|nefield:Password"`
+		Phone    string `validate:"required_if:Role admin|len:12"`
+		Seats    uint   `validate:"required_if:Team true"`
+		Team     bool
+	}
+
+	Booking struct {
+		Start    time.Time
+		End      *time.Time `validate:"gtfield:Start"`
+		MinSeats int        `validate:"ltefield:MaxSeats"`
+		MaxSeats int        `validate:"ltfield:Seats"`
+		Price    *float64   `validate:"gtefield:Deposit"`
+		Deposit  *float64
+		Limit
+	}
+
+	Limit struct {
+		Seats int
+	}
+)
+
+func TestCrossFieldRules(t *testing.T) {
+	start := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	before := start.Add(-time.Hour)
+	price, deposit := 10.0, 20.0
+
+	tests := []struct {
+		name string
+		in   interface{}
+		errs ValidationErrors
+	}{
+		{
+			name: "valid signup",
+			in: Signup{
+				Role: "user", Password: "secret-pass", Confirm: "secret-pass", Login: "john", Phone: "+79001234567",
+			},
+		},
+		{
+			name: "valid admin signup",
+			in: Signup{
+				Role: "admin", Password: "secret-pass", Confirm: "secret-pass", Login: "john",
+				Phone: "+79001234567", Team: true, Seats: 5,
+			},
+		},
+		{
+			name: "invalid signup",
+			in:   Signup{Role: "admin", Password: "secret-pass", Confirm: "secret", Login: "secret-pass", Team: true},
+			errs: ValidationErrors{
+				{Field: "Confirm", Err: ErrEqField},
+				{Field: "Login", Err: ErrNeField},
+				{Field: "Phone", Err: ErrRequired},
+				{Field: "Seats", Err: ErrRequired},
+			},
+		},
+		{
+			name: "conditions are checked with other rules",
+			in:   Signup{Role: "admin", Password: "secret-pass", Confirm: "secret-pass", Login: "john", Phone: "1"},
+			errs: ValidationErrors{{Field: "Phone", Err: ErrLen}},
+		},
+		{
+			name: "valid booking",
+			in:   Booking{Start: start, End: &end, MinSeats: 1, MaxSeats: 1, Limit: Limit{Seats: 2}},
+		},
+		{
+			name: "nil pointers are not compared",
+			in:   Booking{Start: start, Price: &price, MinSeats: -2, MaxSeats: -1},
+		},
+		{
+			name: "invalid booking",
+			in: Booking{
+				Start: start, End: &before, MinSeats: 3, MaxSeats: 2, Limit: Limit{Seats: 2},
+				Price: &price, Deposit: &deposit,
+			},
+			errs: ValidationErrors{
+				{Field: "End", Err: ErrGt},
+				{Field: "MinSeats", Err: ErrLte},
+				{Field: "MaxSeats", Err: ErrLt},
+				{Field: "Price", Err: ErrGte},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+
+			err := Validate(tt.in)
+			if tt.errs == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			requireValidationErrors(t, tt.errs, err)
+		})
+	}
+}
+
+func TestCrossFieldErrors(t *testing.T) {
+	err := Validate(Signup{
+		Role: "admin", Password: "secret-pass", Confirm: "secret", Login: "john", Phone: "+79001234567",
+	})
+
+	var validationErrors ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+
+	// errors are reported on the dependent field, values are not in messages
+	e := validationErrors[0]
+	if e.Field != "Confirm" || e.Rule != "eqfield" || e.Param != "Password" ||
+		e.Err.Error() != "must be equal to Password" {
+		t.Fatalf("unexpected error %+v", e)
+	}
+
+	err = Validate(Signup{Role: "admin", Password: "secret-pass", Confirm: "secret-pass", Login: "john"})
+	if err == nil || err.Error() != "Phone is required" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !errors.As(err, &validationErrors) || validationErrors[0].Err.Error() != "is required if Role is admin" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package hw09structvalidator
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Translator makes a human readable message of a validation error.
+type Translator interface {
+	Message(e ValidationError) string
+}
+
+// Templates translates errors with message templates by rule names, {field} and {param}
+// in a template are replaced with the field path and the rule argument.
+// Errors of rules without a template are described by their Err.
+// Copy the built-in templates before adding templates of custom rules.
+type Templates map[string]string
+
+// Message returns the message of the error made with the template of its rule.
+func (t Templates) Message(e ValidationError) string {
+	template, ok := t[e.Rule]
+	if !ok {
+		return e.Field + ": " + e.Err.Error()
+	}
+
+	return strings.NewReplacer("{field}", e.Field, "{param}", e.Param).Replace(template)
+}
+
+// English and Russian are built-in templates of built-in rules.
+var English = Templates{
+	requiredRule:   "{field} is required",
+	"len":          "{field} must be exactly {param} characters long",
+	"regexp":       "{field} must match the pattern {param}",
+	"in":           "{field} must be one of {param}",
+	"oneof":        "{field} must be one of {param}",
+	"min":          "{field} must be at least {param}",
+	"max":          "{field} must be at most {param}",
+	"email":        "{field} must be a valid email address",
+	"uuid":         "{field} must be a valid UUID",
+	"url":          "{field} must be a valid URL",
+	"ip":           "{field} must be a valid IP address",
+	"gt":           "{field} must be greater than {param}",
+	"gte":          "{field} must be greater than or equal to {param}",
+	"lt":           "{field} must be less than {param}",
+	"lte":          "{field} must be less than or equal to {param}",
+	"min_len":      "{field} must contain at least {param} elements",
+	"max_len":      "{field} must contain at most {param} elements",
+	"prefix":       "{field} must start with {param}",
+	"suffix":       "{field} must end with {param}",
+	"contains":     "{field} must contain {param}",
+	"before":       "{field} must be before {param}",
+	"after":        "{field} must be after {param}",
+	"eqfield":      "{field} must be equal to {param}",
+	"nefield":      "{field} must not be equal to {param}",
+	"gtfield":      "{field} must be greater than {param}",
+	"gtefield":     "{field} must be greater than or equal to {param}",
+	"ltfield":      "{field} must be less than {param}",
+	"ltefield":     "{field} must be less than or equal to {param}",
+	requiredIfRule: "{field} is required",
+}
+
+var Russian = Templates{
+	requiredRule:   "поле {field} обязательно",
+	"len":          "длина поля {field} должна быть равна {param}",
+	"regexp":       "поле {field} должно соответствовать шаблону {param}",
+	"in":           "поле {field} должно быть одним из: {param}",
+	"oneof":        "поле {field} должно быть одним из: {param}",
+	"min":          "поле {field} должно быть не меньше {param}",
+	"max":          "поле {field} должно быть не больше {param}",
+	"email":        "поле {field} должно быть корректным email-адресом",
+	"uuid":         "поле {field} должно быть корректным UUID",
+	"url":          "поле {field} должно быть корректным URL",
+	"ip":           "поле {field} должно быть корректным IP-адресом",
+	"gt":           "поле {field} должно быть больше {param}",
+	"gte":          "поле {field} должно быть не меньше {param}",
+	"lt":           "поле {field} должно быть меньше {param}",
+	"lte":          "поле {field} должно быть не больше {param}",
+	"min_len":      "поле {field} должно содержать не менее {param} элементов",
+	"max_len":      "поле {field} должно содержать не более {param} элементов",
+	"prefix":       "поле {field} должно начинаться с {param}",
+	"suffix":       "поле {field} должно заканчиваться на {param}",
+	"contains":     "поле {field} должно содержать {param}",
+	"before":       "поле {field} должно быть раньше {param}",
+	"after":        "поле {field} должно быть позже {param}",
+	"eqfield":      "поле {field} должно быть равно полю {param}",
+	"nefield":      "поле {field} не должно быть равно полю {param}",
+	"gtfield":      "поле {field} должно быть больше поля {param}",
+	"gtefield":     "поле {field} должно быть не меньше поля {param}",
+	"ltfield":      "поле {field} должно быть меньше поля {param}",
+	"ltefield":     "поле {field} должно быть не больше поля {param}",
+	requiredIfRule: "поле {field} обязательно",
+}
+
+// FieldError is a machine readable validation error.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param"`
+	Message string `json:"message"`
+}
+
+// Error joins English messages of errors with "; ", for example
+// "Age must be at least 18; Phones[0] must be exactly 11 characters long".
+func (v ValidationErrors) Error() string {
+	messages := make([]string, 0, len(v))
+	for _, e := range v.Translate(English) {
+		messages = append(messages, e.Message)
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Translate returns errors with messages made by the translator.
+func (v ValidationErrors) Translate(tr Translator) []FieldError {
+	result := make([]FieldError, 0, len(v))
+	for _, e := range v {
+		result = append(result, FieldError{Field: e.Field, Rule: e.Rule, Param: e.Param, Message: tr.Message(e)})
+	}
+
+	return result
+}
+
+// MarshalJSON encodes errors as an array of FieldError with English messages.
+// Use Translate to encode messages in another language.
+func (v ValidationErrors) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.Translate(English))
+}
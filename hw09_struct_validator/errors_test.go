@@ -0,0 +1,84 @@
+package hw09structvalidator
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestValidationErrorsJSON(t *testing.T) {
+	err := Validate(User{ID: validUser.ID, Age: 10, Email: validUser.Email, Role: "guest", Phones: []string{"1"}})
+
+	var validationErrors ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+
+	data, err := json.Marshal(validationErrors)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[` +
+		`{"field":"Age","rule":"min","param":"18","message":"Age must be at least 18"},` +
+		`{"field":"Role","rule":"in","param":"admin,stuff","message":"Role must be one of admin,stuff"},` +
+		`{"field":"Phones[0]","rule":"len","param":"11","message":"Phones[0] must be exactly 11 characters long"}` +
+		`]`
+	if string(data) != expected {
+		t.Fatalf("expected %s, got %s", expected, data)
+	}
+}
+
+func TestValidationErrorsTranslate(t *testing.T) {
+	err := Validate(User{ID: validUser.ID, Age: 60, Email: validUser.Email, Role: "admin"})
+
+	var validationErrors ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+
+	messages := validationErrors.Translate(Russian)
+	if len(messages) != 1 || messages[0].Message != "поле Age должно быть не больше 50" {
+		t.Fatalf("unexpected messages: %+v", messages)
+	}
+
+	t.Run("built-in templates", func(t *testing.T) {
+		for rule := range builtinRules {
+			if rule == nestedRule {
+				continue
+			}
+			if _, ok := English[rule]; !ok {
+				t.Errorf("no English template of %s", rule)
+			}
+			if _, ok := Russian[rule]; !ok {
+				t.Errorf("no Russian template of %s", rule)
+			}
+		}
+	})
+
+	t.Run("custom rules", func(t *testing.T) {
+		v := New()
+		if err := v.RegisterRule("inn", innRule); err != nil {
+			t.Fatal(err)
+		}
+
+		err := v.Validate(struct {
+			INN string `validate:"inn"`
+		}{INN: "7707083890"})
+
+		var validationErrors ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			t.Fatalf("expected ValidationErrors, got %v", err)
+		}
+
+		// rules without templates are described by their errors
+		if err.Error() != "INN: is not a valid INN: invalid checksum" {
+			t.Fatalf("unexpected message %q", err)
+		}
+
+		templates := Templates{"inn": "{field} must be a valid INN"}
+		if msg := validationErrors.Translate(templates)[0].Message; msg != "INN must be a valid INN" {
+			t.Fatalf("unexpected message %q", msg)
+		}
+	})
+}
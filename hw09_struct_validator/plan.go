@@ -0,0 +1,79 @@
+package hw09structvalidator
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// structPlan is a compiled validation of a struct type: tags of its fields are parsed,
+// regexps are compiled and plans of nested structs are resolved.
+type structPlan struct {
+	fields []fieldPlan
+}
+
+type fieldPlan struct {
+	index int
+	name  string
+	rules *fieldRules
+}
+
+// planEntry is a cached plan or a programmer error of the type.
+type planEntry struct {
+	plan *structPlan
+	err  error
+}
+
+func (vl *Validator) resetPlans() {
+	vl.plans.Store(&sync.Map{})
+}
+
+// plan returns the cached plan of the struct type, compiling it on the first use.
+func (vl *Validator) plan(t reflect.Type) (*structPlan, error) {
+	plans := vl.plans.Load()
+	if e, ok := plans.Load(t); ok {
+		return e.(*planEntry).plan, e.(*planEntry).err
+	}
+
+	plan, err := vl.compile(t, "", make(map[reflect.Type]*structPlan))
+	e, _ := plans.LoadOrStore(t, &planEntry{plan: plan, err: err})
+
+	return e.(*planEntry).plan, e.(*planEntry).err
+}
+
+// compile makes a plan of the struct type, prefix is a path of the struct used in errors.
+// Plans being compiled are kept in compiling, so recursive types refer to the same plan.
+func (vl *Validator) compile(
+	t reflect.Type, prefix string, compiling map[reflect.Type]*structPlan,
+) (*structPlan, error) {
+	if plan, ok := compiling[t]; ok {
+		return plan, nil
+	}
+
+	plan := &structPlan{}
+	compiling[t] = plan
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get(tagName)
+		if tag == "" || !field.IsExported() {
+			continue
+		}
+
+		path := prefix + field.Name
+		rules, err := vl.parseRules(tag, field.Type, t)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", path, err)
+		}
+
+		if rules.nested {
+			if rules.plan, err = vl.compile(scalarType(field.Type), path+".", compiling); err != nil {
+				return nil, err
+			}
+		}
+
+		plan.fields = append(plan.fields, fieldPlan{index: i, name: field.Name, rules: rules})
+	}
+
+	return plan, nil
+}
@@ -0,0 +1,121 @@
+package hw09structvalidator
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type Node struct {
+	Name     string  `validate:"len:1"`
+	Children []*Node `validate:"nested"`
+	Parent   *Node   `validate:"nested"`
+}
+
+type Broken struct {
+	Inner struct {
+		Name string `validate:"len:x"`
+	} `validate:"nested"`
+}
+
+func TestPlanCache(t *testing.T) {
+	t.Run("recursive type", func(t *testing.T) {
+		root := &Node{Name: "r"}
+		root.Children = []*Node{{Name: "a", Parent: root}, {Name: "bb", Children: []*Node{{Name: ""}}}}
+
+		requireValidationErrors(t, ValidationErrors{
+			{Field: "Children[1].Name", Err: ErrLen},
+			{Field: "Children[1].Children[0].Name", Err: ErrLen},
+		}, Validate(root))
+	})
+
+	t.Run("plan is compiled once", func(t *testing.T) {
+		v := New()
+		plan, err := v.plan(reflect.TypeOf(User{}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		again, _ := v.plan(reflect.TypeOf(User{}))
+		if plan != again {
+			t.Fatal("the plan is not cached")
+		}
+	})
+
+	t.Run("programmer errors are cached", func(t *testing.T) {
+		v := New()
+		for i := 0; i < 2; i++ {
+			err := v.Validate(Broken{})
+			if !errors.Is(err, ErrInvalidTag) || err.Error() != `field Inner.Name: len: invalid validate tag: length "x"` {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+	})
+
+	t.Run("registered rule resets plans", func(t *testing.T) {
+		v := New()
+		if err := v.Validate(Company{}); !errors.Is(err, ErrUnknownRule) {
+			t.Fatalf("expected ErrUnknownRule, got %v", err)
+		}
+
+		for name, fn := range map[string]RuleFunc{"inn": innRule, "phone_ru": phoneRule, "divisible": divisibleRule} {
+			if err := v.RegisterRule(name, fn); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		requireValidationErrors(t, ValidationErrors{
+			{Field: "INN", Err: ErrRequired},
+			{Field: "Phones", Err: ErrMinLen},
+			{Field: "Seats", Err: ErrMin},
+		}, v.Validate(Company{}))
+	})
+}
+
+func TestValidateAllocations(t *testing.T) {
+	v := New()
+	user, manyPhones := validUser, validUser
+	for i := 0; i < 100; i++ {
+		manyPhones.Phones = append(manyPhones.Phones, "79001234567")
+	}
+
+	allocs := testing.AllocsPerRun(100, func() { _ = v.Validate(&user) })
+	if many := testing.AllocsPerRun(100, func() { _ = v.Validate(&manyPhones) }); many != allocs {
+		t.Fatalf("allocations of a valid value must not depend on its size: %v and %v", allocs, many)
+	}
+}
+
+// BenchmarkValidate compares cached plans with compiling tags on every call, as a naive implementation does.
+func BenchmarkValidate(b *testing.B) {
+	invalidUser := validUser
+	invalidUser.Age = 10
+	invalidUser.Phones = []string{"1", "79001234567"}
+
+	values := map[string]interface{}{
+		"valid":    &validUser,
+		"invalid":  &invalidUser,
+		"nested":   &Customer{Name: "Anna", Shipping: []Address{{City: "Kazan", Zip: "420000"}}},
+		"response": &Response{Code: 200},
+	}
+
+	for name, value := range values {
+		value := value
+
+		b.Run(name+"/cached", func(b *testing.B) {
+			v := New()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = v.Validate(value)
+			}
+		})
+
+		b.Run(name+"/naive", func(b *testing.B) {
+			v := New()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				v.resetPlans()
+				_ = v.Validate(value)
+			}
+		})
+	}
+}
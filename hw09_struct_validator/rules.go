@@ -0,0 +1,439 @@
+package hw09structvalidator
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validation errors, they are wrapped by ValidationError.Err.
+var (
+	ErrRequired = errors.New("is required")
+	ErrLen      = errors.New("length is invalid")
+	ErrRegexp   = errors.New("does not match the pattern")
+	ErrIn       = errors.New("is not in the allowed set")
+	ErrMin      = errors.New("is less than the minimum")
+	ErrMax      = errors.New("is greater than the maximum")
+	ErrEmail    = errors.New("is not a valid email")
+	ErrUUID     = errors.New("is not a valid UUID")
+	ErrURL      = errors.New("is not a valid URL")
+	ErrIP       = errors.New("is not a valid IP address")
+	ErrGt       = errors.New("must be greater than")
+	ErrGte      = errors.New("must be greater than or equal to")
+	ErrLt       = errors.New("must be less than")
+	ErrLte      = errors.New("must be less than or equal to")
+	ErrMinLen   = errors.New("has too few elements")
+	ErrMaxLen   = errors.New("has too many elements")
+	ErrPrefix   = errors.New("does not have the prefix")
+	ErrSuffix   = errors.New("does not have the suffix")
+	ErrContains = errors.New("does not contain")
+	ErrBefore   = errors.New("must be before")
+	ErrAfter    = errors.New("must be after")
+)
+
+const (
+	nestedRule   = "nested"
+	requiredRule = "required"
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	uuidRe   = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// builtinRules are names which can't be used for custom rules.
+var builtinRules = map[string]bool{
+	nestedRule: true, requiredRule: true, "min_len": true, "max_len": true,
+	"len": true, "regexp": true, "in": true, "prefix": true, "suffix": true, "contains": true,
+	"email": true, "uuid": true, "url": true, "ip": true,
+	"min": true, "max": true, "oneof": true, "gt": true, "gte": true, "lt": true, "lte": true,
+	"before": true, "after": true,
+	"eqfield": true, "nefield": true, "gtfield": true, "gtefield": true, "ltfield": true, "ltefield": true,
+	requiredIfRule: true,
+}
+
+// noArgRules are built-in rules without an argument, others require one.
+var noArgRules = map[string]bool{
+	nestedRule: true, requiredRule: true, "email": true, "uuid": true, "url": true, "ip": true,
+}
+
+// BuiltinRule reports whether name is a built-in rule and whether the rule requires an argument.
+// Code generators use it to accept the same rules as the validator.
+func BuiltinRule(name string) (builtin, requiresArg bool) {
+	return builtinRules[name], builtinRules[name] && !noArgRules[name]
+}
+
+// check validates a value, it returns a validation error or nil.
+type check func(v reflect.Value) error
+
+// ruleCheck is a check of the rule with the argument (param) from a tag.
+type ruleCheck struct {
+	rule  string
+	param string
+	check check
+}
+
+// fieldRules are parsed rules of a field.
+type fieldRules struct {
+	nested   bool
+	required bool
+	// plan of nested structs, set when the plan of the field struct is compiled.
+	plan *structPlan
+	// field checks apply to the field itself (under pointers).
+	field []ruleCheck
+	// checks apply to scalar values: the field, elements of containers, pointed values.
+	checks []ruleCheck
+	// cross checks compare the field (under pointers) with sibling fields.
+	cross []crossCheck
+	// requiredIf are conditions on sibling fields making the field required.
+	requiredIf []condition
+}
+
+// parseRules parses a tag for a field of type t in the struct type parent. Scalar rules are checked
+// against the type found under pointers and containers. Structs other than time.Time are validated
+// with nested (it can be combined with field rules) or with custom rules.
+// Cross-field rules refer to fields of parent.
+func (vl *Validator) parseRules(tag string, t, parent reflect.Type) (*fieldRules, error) {
+	base := scalarType(t)
+	rules := &fieldRules{}
+
+	for _, r := range strings.Split(tag, "|") {
+		name, arg, hasArg := strings.Cut(r, ":")
+		if fn := vl.rule(name); fn != nil {
+			rules.checks = append(rules.checks, ruleCheck{rule: name, param: arg, check: customCheck(fn, arg)})
+			continue
+		}
+
+		if name == "" || (builtinRules[name] && hasArg == noArgRules[name]) {
+			return nil, fmt.Errorf("%w: %q, expected rule or rule:argument", ErrInvalidTag, r)
+		}
+
+		switch name {
+		case nestedRule:
+			rules.nested = true
+		case requiredRule:
+			rules.required = true
+		case "min_len", "max_len":
+			c, err := newLenCheck(name, arg, t)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			rules.field = append(rules.field, ruleCheck{rule: name, param: arg, check: c})
+		case "eqfield", "nefield", "gtfield", "gtefield", "ltfield", "ltefield":
+			c, err := newCrossCheck(name, arg, t, parent)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			rules.cross = append(rules.cross, c)
+		case requiredIfRule:
+			c, err := newCondition(arg, parent)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			rules.requiredIf = append(rules.requiredIf, c)
+		default:
+			c, err := newCheck(name, arg, base)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			rules.checks = append(rules.checks, ruleCheck{rule: name, param: arg, check: c})
+		}
+	}
+
+	isStruct := base.Kind() == reflect.Struct && base != timeType
+	switch {
+	case rules.nested && !isStruct:
+		return nil, fmt.Errorf("%w: nested for %s", ErrInvalidTag, t)
+	case isStruct && !rules.nested && len(rules.checks) == 0:
+		return nil, fmt.Errorf("%w: %s, structs require nested", ErrInvalidTag, t)
+	}
+
+	return rules, nil
+}
+
+func customCheck(fn RuleFunc, arg string) check {
+	return func(v reflect.Value) error {
+		return fn(v, arg)
+	}
+}
+
+// scalarType returns the type under pointers, slices, arrays and maps.
+func scalarType(t reflect.Type) reflect.Type {
+	for {
+		switch t.Kind() { //nolint:exhaustive
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+}
+
+func newCheck(name, arg string, t reflect.Type) (check, error) {
+	switch {
+	case t.Kind() == reflect.String:
+		return newStringCheck(name, arg)
+	case isInt(t.Kind()):
+		return newIntCheck(name, arg)
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return newFloatCheck(name, arg)
+	case t == timeType:
+		return newTimeCheck(name, arg)
+	case t.Kind() == reflect.Struct:
+		return nil, fmt.Errorf("%w for structs", ErrUnknownRule)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, t)
+	}
+}
+
+// newLenCheck makes min_len and max_len checks of a slice, an array, a map or a string (in runes).
+func newLenCheck(name, arg string, t reflect.Type) (check, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() { //nolint:exhaustive
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.String:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, t)
+	}
+
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("%w: length %q", ErrInvalidTag, arg)
+	}
+
+	length := func(v reflect.Value) int {
+		if v.Kind() == reflect.String {
+			return len([]rune(v.String()))
+		}
+		return v.Len()
+	}
+
+	if name == "min_len" {
+		return func(v reflect.Value) error {
+			if l := length(v); l < n {
+				return fmt.Errorf("%w: %d < %d", ErrMinLen, l, n)
+			}
+			return nil
+		}, nil
+	}
+
+	return func(v reflect.Value) error {
+		if l := length(v); l > n {
+			return fmt.Errorf("%w: %d > %d", ErrMaxLen, l, n)
+		}
+		return nil
+	}, nil
+}
+
+func newStringCheck(name, arg string) (check, error) {
+	switch name {
+	case "len":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%w: length %q", ErrInvalidTag, arg)
+		}
+		return func(v reflect.Value) error {
+			if l := len([]rune(v.String())); l != n {
+				return fmt.Errorf("%w: expected %d, got %d", ErrLen, n, l)
+			}
+			return nil
+		}, nil
+	case "regexp":
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidTag, err)
+		}
+		return stringCheck(ErrRegexp, arg, re.MatchString), nil
+	case "in":
+		set := make(map[string]struct{})
+		for _, s := range strings.Split(arg, ",") {
+			set[s] = struct{}{}
+		}
+		return stringCheck(ErrIn, "{"+arg+"}", func(s string) bool {
+			_, ok := set[s]
+			return ok
+		}), nil
+	case "prefix":
+		return stringCheck(ErrPrefix, strconv.Quote(arg), func(s string) bool { return strings.HasPrefix(s, arg) }), nil
+	case "suffix":
+		return stringCheck(ErrSuffix, strconv.Quote(arg), func(s string) bool { return strings.HasSuffix(s, arg) }), nil
+	case "contains":
+		return stringCheck(ErrContains, strconv.Quote(arg), func(s string) bool { return strings.Contains(s, arg) }), nil
+	case "email":
+		return stringCheck(ErrEmail, "", isEmail), nil
+	case "uuid":
+		return stringCheck(ErrUUID, "", uuidRe.MatchString), nil
+	case "url":
+		return stringCheck(ErrURL, "", isURL), nil
+	case "ip":
+		return stringCheck(ErrIP, "", func(s string) bool { return net.ParseIP(s) != nil }), nil
+	default:
+		return nil, fmt.Errorf("%w for strings", ErrUnknownRule)
+	}
+}
+
+// stringCheck returns a check failing with "value err arg" unless ok.
+func stringCheck(err error, arg string, ok func(s string) bool) check {
+	return func(v reflect.Value) error {
+		if ok(v.String()) {
+			return nil
+		}
+		if arg == "" {
+			return fmt.Errorf("%q %w", v.String(), err)
+		}
+		return fmt.Errorf("%q %w %s", v.String(), err, arg)
+	}
+}
+
+// isEmail accepts a bare address such as user@example.com, without a display name or angle brackets.
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Name == "" && addr.Address == s
+}
+
+// isURL accepts absolute URLs with a host.
+func isURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+func newIntCheck(name, arg string) (check, error) {
+	switch name {
+	case "min", "max":
+		limit, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: number %q", ErrInvalidTag, arg)
+		}
+		if name == "min" {
+			return func(v reflect.Value) error {
+				if v.Int() < limit {
+					return fmt.Errorf("%d %w %d", v.Int(), ErrMin, limit)
+				}
+				return nil
+			}, nil
+		}
+		return func(v reflect.Value) error {
+			if v.Int() > limit {
+				return fmt.Errorf("%d %w %d", v.Int(), ErrMax, limit)
+			}
+			return nil
+		}, nil
+	case "in", "oneof":
+		// in takes comma separated numbers, oneof space separated ones
+		values := strings.Split(arg, ",")
+		if name == "oneof" {
+			values = strings.Fields(arg)
+		}
+
+		set := make(map[int64]struct{}, len(values))
+		for _, s := range values {
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: number %q", ErrInvalidTag, s)
+			}
+			set[n] = struct{}{}
+		}
+		return func(v reflect.Value) error {
+			if _, ok := set[v.Int()]; !ok {
+				return fmt.Errorf("%d %w {%s}", v.Int(), ErrIn, arg)
+			}
+			return nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w for integers", ErrUnknownRule)
+	}
+}
+
+func newFloatCheck(name, arg string) (check, error) {
+	var (
+		err error
+		ok  func(a, b float64) bool
+	)
+
+	switch name {
+	case "gt":
+		err, ok = ErrGt, func(a, b float64) bool { return a > b }
+	case "gte":
+		err, ok = ErrGte, func(a, b float64) bool { return a >= b }
+	case "lt":
+		err, ok = ErrLt, func(a, b float64) bool { return a < b }
+	case "lte":
+		err, ok = ErrLte, func(a, b float64) bool { return a <= b }
+	default:
+		return nil, fmt.Errorf("%w for floats", ErrUnknownRule)
+	}
+
+	limit, parseErr := strconv.ParseFloat(arg, 64)
+	if parseErr != nil {
+		return nil, fmt.Errorf("%w: number %q", ErrInvalidTag, arg)
+	}
+
+	return func(v reflect.Value) error {
+		if !ok(v.Float(), limit) {
+			return fmt.Errorf("%g %w %g", v.Float(), err, limit)
+		}
+		return nil
+	}, nil
+}
+
+// timeLayouts are accepted in arguments of before and after.
+var timeLayouts = []string{time.RFC3339, "2006-01-02"}
+
+func newTimeCheck(name, arg string) (check, error) {
+	var (
+		err error
+		ok  func(t, limit time.Time) bool
+	)
+
+	switch name {
+	case "before":
+		err, ok = ErrBefore, time.Time.Before
+	case "after":
+		err, ok = ErrAfter, time.Time.After
+	default:
+		return nil, fmt.Errorf("%w for time", ErrUnknownRule)
+	}
+
+	limit, parseErr := parseTime(arg)
+	if parseErr != nil {
+		return nil, fmt.Errorf("%w: time %q, expected RFC 3339 or 2006-01-02", ErrInvalidTag, arg)
+	}
+
+	return func(v reflect.Value) error {
+		t := v.Interface().(time.Time)
+		if !ok(t, limit) {
+			return fmt.Errorf("%s %w %s", t.Format(time.RFC3339), err, limit.Format(time.RFC3339))
+		}
+		return nil
+	}, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	var err error
+	for _, layout := range timeLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, err
+}
+
+func isInt(k reflect.Kind) bool {
+	switch k { //nolint:exhaustive
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	default:
+		return false
+	}
+}
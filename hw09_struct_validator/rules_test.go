@@ -0,0 +1,232 @@
+package hw09structvalidator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type (
+	Event struct {
+		ID       string            `validate:"required|uuid"`
+		Owner    *string           `validate:"required|email"`
+		Link     string            `validate:"url"`
+		Hosts    []string          `validate:"min_len:1|max_len:2|ip"`
+		Status   int               `validate:"oneof:1 2 3"`
+		Price    float64           `validate:"gt:0|lte:1000"`
+		Discount float32           `validate:"gte:0|lt:1"`
+		Code     string            `validate:"prefix:EV-|suffix:-X|contains:2024"`
+		Start    time.Time         `validate:"after:2020-01-01|before:2030-01-01T00:00:00Z"`
+		Tags     map[string]string `validate:"max_len:1"`
+		Title    string            `validate:"min_len:2|max_len:5"`
+		Speakers []Address         `validate:"required|nested"`
+	}
+)
+
+func validEvent() Event {
+	owner := "owner@example.com"
+
+	return Event{
+		ID:       "3F2B1C4E-8D6A-4E7B-9C1D-2A5F6E8B7C9D",
+		Owner:    &owner,
+		Link:     "https://example.com/events/1?ref=mail",
+		Hosts:    []string{"10.0.0.1", "::1"},
+		Status:   2,
+		Price:    1000,
+		Discount: 0,
+		Code:     "EV-2024-X",
+		Start:    time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC),
+		Title:    "Go",
+		Speakers: []Address{{City: "Kazan", Zip: "420000"}},
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(e *Event)
+		errs   ValidationErrors
+	}{
+		{name: "valid", modify: func(*Event) {}},
+		{
+			name: "required",
+			modify: func(e *Event) {
+				e.ID, e.Owner, e.Speakers = "", nil, []Address{}
+			},
+			errs: ValidationErrors{
+				{Field: "ID", Err: ErrRequired},
+				{Field: "Owner", Err: ErrRequired},
+				{Field: "Speakers", Err: ErrRequired},
+			},
+		},
+		{
+			name: "formats",
+			modify: func(e *Event) {
+				owner := "Owner <owner@example.com>"
+				e.ID, e.Owner, e.Link = "3f2b1c4e-8d6a-4e7b-9c1d", &owner, "/events/1"
+				e.Hosts = []string{"10.0.0.256", "localhost"}
+			},
+			errs: ValidationErrors{
+				{Field: "ID", Err: ErrUUID},
+				{Field: "Owner", Err: ErrEmail},
+				{Field: "Link", Err: ErrURL},
+				{Field: "Hosts[0]", Err: ErrIP},
+				{Field: "Hosts[1]", Err: ErrIP},
+			},
+		},
+		{
+			name: "numbers",
+			modify: func(e *Event) {
+				e.Status, e.Price, e.Discount = 4, 0, 1
+			},
+			errs: ValidationErrors{
+				{Field: "Status", Err: ErrIn},
+				{Field: "Price", Err: ErrGt},
+				{Field: "Discount", Err: ErrLt},
+			},
+		},
+		{
+			name: "upper bounds",
+			modify: func(e *Event) {
+				e.Price, e.Discount = 1000.5, -0.1
+			},
+			errs: ValidationErrors{
+				{Field: "Price", Err: ErrLte},
+				{Field: "Discount", Err: ErrGte},
+			},
+		},
+		{
+			name: "lengths",
+			modify: func(e *Event) {
+				e.Hosts = []string{"10.0.0.1", "10.0.0.2", "x"}
+				e.Tags = map[string]string{"a": "", "b": ""}
+				e.Title = "Ё"
+			},
+			errs: ValidationErrors{
+				{Field: "Hosts", Err: ErrMaxLen},
+				{Field: "Hosts[2]", Err: ErrIP},
+				{Field: "Tags", Err: ErrMaxLen},
+				{Field: "Title", Err: ErrMinLen},
+			},
+		},
+		{
+			name: "empty not required",
+			modify: func(e *Event) {
+				e.Hosts, e.Title = nil, "Конфа"
+			},
+			errs: ValidationErrors{{Field: "Hosts", Err: ErrMinLen}},
+		},
+		{
+			name: "substrings",
+			modify: func(e *Event) {
+				e.Code = "ev-2023-Y"
+			},
+			errs: ValidationErrors{
+				{Field: "Code", Err: ErrPrefix},
+				{Field: "Code", Err: ErrSuffix},
+				{Field: "Code", Err: ErrContains},
+			},
+		},
+		{
+			name: "too early",
+			modify: func(e *Event) {
+				e.Start = time.Date(2019, 12, 31, 23, 59, 0, 0, time.UTC)
+			},
+			errs: ValidationErrors{{Field: "Start", Err: ErrAfter}},
+		},
+		{
+			name: "too late",
+			modify: func(e *Event) {
+				e.Start = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+			},
+			errs: ValidationErrors{{Field: "Start", Err: ErrBefore}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+
+			e := validEvent()
+			tt.modify(&e)
+
+			err := Validate(e)
+			if tt.errs == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			requireValidationErrors(t, tt.errs, err)
+		})
+	}
+}
+
+func TestBuiltinRule(t *testing.T) {
+	tests := []struct {
+		name        string
+		builtin     bool
+		requiresArg bool
+	}{
+		{name: "nested", builtin: true},
+		{name: "email", builtin: true},
+		{name: "len", builtin: true, requiresArg: true},
+		{name: "required_if", builtin: true, requiresArg: true},
+		{name: "inn"},
+		{name: ""},
+	}
+
+	for _, tt := range tests {
+		builtin, requiresArg := BuiltinRule(tt.name)
+		if builtin != tt.builtin || requiresArg != tt.requiresArg {
+			t.Errorf("%q: expected %v, %v, got %v, %v", tt.name, tt.builtin, tt.requiresArg, builtin, requiresArg)
+		}
+	}
+}
+
+func TestParseRulesErrors(t *testing.T) {
+	tests := []struct {
+		tag         string
+		value       interface{}
+		expectedErr error
+	}{
+		{tag: "email:strict", value: "", expectedErr: ErrInvalidTag},
+		{tag: "required:true", value: "", expectedErr: ErrInvalidTag},
+		{tag: "prefix", value: "", expectedErr: ErrInvalidTag},
+		{tag: "len:1|", value: "", expectedErr: ErrInvalidTag},
+		{tag: "oneof:1,2", value: 0, expectedErr: ErrInvalidTag},
+		{tag: "gt:zero", value: 0.0, expectedErr: ErrInvalidTag},
+		{tag: "min:0", value: 0.0, expectedErr: ErrUnknownRule},
+		{tag: "email", value: 0, expectedErr: ErrUnknownRule},
+		{tag: "min_len:1", value: 0, expectedErr: ErrUnsupportedType},
+		{tag: "max_len:-1", value: []int{}, expectedErr: ErrInvalidTag},
+		{tag: "before:tomorrow", value: time.Time{}, expectedErr: ErrInvalidTag},
+		{tag: "min:1", value: time.Time{}, expectedErr: ErrUnknownRule},
+		{tag: "nested", value: time.Time{}, expectedErr: ErrInvalidTag},
+		{tag: "nested|len:1", value: Address{}, expectedErr: ErrUnknownRule},
+		{tag: "required|nested|max_len:1", value: []Address{}, expectedErr: nil},
+		{tag: "eqfield:Missing", value: "", expectedErr: ErrInvalidTag},
+		{tag: "eqfield:ID", value: 0, expectedErr: ErrInvalidTag},
+		{tag: "gtfield:Start", value: new(time.Time), expectedErr: nil},
+		{tag: "ltfield:Tags", value: map[string]string{}, expectedErr: ErrUnsupportedType},
+		{tag: "required_if:Status", value: "", expectedErr: ErrInvalidTag},
+		{tag: "required_if:Status two", value: "", expectedErr: ErrInvalidTag},
+		{tag: "required_if:Hosts 1", value: "", expectedErr: ErrUnsupportedType},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%T %s", tt.value, tt.tag), func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+
+			_, err := New().parseRules(tt.tag, reflect.TypeOf(tt.value), reflect.TypeOf(Event{}))
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
@@ -1,17 +1,360 @@
 package hw09structvalidator
 
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Programmer errors: the value or its tags can't be validated at all.
+var (
+	ErrNotStruct       = errors.New("value is not a struct")
+	ErrInvalidTag      = errors.New("invalid validate tag")
+	ErrUnknownRule     = errors.New("unknown rule")
+	ErrUnsupportedType = errors.New("unsupported field type")
+	ErrInvalidRuleName = errors.New("invalid rule name")
+)
+
+const tagName = "validate"
+
 type ValidationError struct {
+	// Field is a path to the value: Name, Address.Zip, Phones[2], Labels[key].
 	Field string
+	// Rule and Param are the failed rule and its argument from the tag.
+	Rule  string
+	Param string
 	Err   error
 }
 
 type ValidationErrors []ValidationError
 
-func (v ValidationErrors) Error() string {
-	panic("implement me")
+// RuleFunc checks a value of a field or an element of a field container, arg is the rule argument
+// (empty if the rule has no argument). It returns a validation error or an error wrapping ErrInvalidTag
+// if the argument is malformed.
+type RuleFunc func(v reflect.Value, arg string) error
+
+// Validator validates structs with built-in rules and custom rules registered with RegisterRule.
+// It is safe for concurrent use.
+// Validation plans of struct types are compiled once and cached.
+type Validator struct {
+	mu    sync.RWMutex
+	rules map[string]RuleFunc
+
+	// plans maps reflect.Type to *planEntry, the map is replaced when rules change.
+	plans atomic.Pointer[sync.Map]
+}
+
+var defaultValidator = New()
+
+func New() *Validator {
+	vl := &Validator{rules: make(map[string]RuleFunc)}
+	vl.resetPlans()
+
+	return vl
+}
+
+// RegisterRule adds a custom rule or replaces a custom rule with the same name.
+// Names of built-in rules can't be used, neither can names containing ':', '|' or spaces.
+func (vl *Validator) RegisterRule(name string, fn func(v reflect.Value, arg string) error) error {
+	if name == "" || strings.ContainsAny(name, ":| \t") || builtinRules[name] || fn == nil {
+		return fmt.Errorf("%w: %q", ErrInvalidRuleName, name)
+	}
+
+	vl.mu.Lock()
+	defer vl.mu.Unlock()
+
+	vl.rules[name] = fn
+	// plans compiled with the previous rules are dropped, tags using the rule were unknown
+	vl.resetPlans()
+
+	return nil
+}
+
+// RegisterRule adds a custom rule to the default Validator used by Validate.
+func RegisterRule(name string, fn func(v reflect.Value, arg string) error) error {
+	return defaultValidator.RegisterRule(name, fn)
+}
+
+func (vl *Validator) rule(name string) RuleFunc {
+	vl.mu.RLock()
+	defer vl.mu.RUnlock()
+
+	return vl.rules[name]
 }
 
+// Validate validates the value with the default Validator, see (*Validator).Validate.
 func Validate(v interface{}) error {
-	// Place your code here.
+	return defaultValidator.Validate(v)
+}
+
+// Validate validates exported fields of a struct (or a pointer to a struct) by their validate tags.
+// It returns ValidationErrors with all failed checks or a programmer error (wrapping ErrNotStruct,
+// ErrInvalidTag, ErrUnknownRule or ErrUnsupportedType) if the tags can't be applied.
+//
+// Rules of a slice, an array or a map apply to its elements, nil pointers are not validated.
+// The exceptions are required, min_len and max_len which apply to the field itself.
+// Structs, pointers to structs and containers of them tagged with validate:"nested" are validated recursively,
+// pointer cycles are not followed.
+// Cross-field rules such as eqfield:Password, gtfield:Start and required_if:Role admin refer to fields
+// of the same struct, their errors are reported on the field with the tag.
+func (vl *Validator) Validate(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	w := walker{path: make(path, 0, 8)}
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		w.root = visit{ptr: rv.Pointer(), typ: rv.Type()}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: %T", ErrNotStruct, v)
+	}
+
+	plan, err := vl.plan(rv.Type())
+	if err != nil {
+		return err
+	}
+
+	if err := w.validateStruct(rv, plan); err != nil {
+		return err
+	}
+
+	if len(w.errs) > 0 {
+		return w.errs
+	}
+
 	return nil
 }
+
+// walker validates a value with a plan, collecting errors. The path to the current value is built
+// only when an error is reported, so valid values don't allocate.
+type walker struct {
+	path path
+	errs ValidationErrors
+	// root and visiting are pointers to structs being validated, a pointer cycle is not followed again.
+	root     visit
+	visiting map[visit]bool
+}
+
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+func (w *walker) validateStruct(v reflect.Value, plan *structPlan) error {
+	for i := range plan.fields {
+		f := &plan.fields[i]
+
+		w.path = append(w.path, pathElem{field: f.name})
+		err := w.validateField(v, v.Field(f.index), f.rules)
+		w.path = w.path[:len(w.path)-1]
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateField applies field rules of the field of the parent struct and then the rest of rules to its values.
+// Other rules are not checked if a required field is empty.
+func (w *walker) validateField(parent, v reflect.Value, rules *fieldRules) error {
+	if (rules.required || len(rules.requiredIf) > 0) && isEmpty(v) {
+		if rules.required {
+			w.fail(requiredRule, "", ErrRequired)
+			return nil
+		}
+		for i := range rules.requiredIf {
+			if c := &rules.requiredIf[i]; c.met(parent) {
+				w.fail(requiredIfRule, c.param, c.err)
+				return nil
+			}
+		}
+	}
+
+	if value := indirect(v); value.IsValid() {
+		if err := w.applyChecks(rules.field, value); err != nil {
+			return err
+		}
+		w.applyCross(parent, rules.cross, value)
+	}
+
+	return w.validateValue(v, rules)
+}
+
+// validateValue applies rules to v, elements of containers and pointed values.
+func (w *walker) validateValue(v reflect.Value, rules *fieldRules) error {
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Ptr && rules.plan != nil {
+			return w.validatePointer(v, rules)
+		}
+		return w.validateValue(v.Elem(), rules)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			w.path = append(w.path, pathElem{index: i})
+			err := w.validateValue(v.Index(i), rules)
+			w.path = w.path[:len(w.path)-1]
+
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if v.Len() == 0 {
+			return nil
+		}
+		for _, key := range sortedKeys(v) {
+			w.path = append(w.path, pathElem{key: key})
+			err := w.validateValue(v.MapIndex(key), rules)
+			w.path = w.path[:len(w.path)-1]
+
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		if err := w.applyChecks(rules.checks, v); err != nil {
+			return err
+		}
+		if rules.plan != nil {
+			return w.validateStruct(v, rules.plan)
+		}
+		return nil
+	default:
+		return w.applyChecks(rules.checks, v)
+	}
+}
+
+func (w *walker) validatePointer(v reflect.Value, rules *fieldRules) error {
+	key := visit{ptr: v.Pointer(), typ: v.Type()}
+	if key == w.root || w.visiting[key] {
+		return nil
+	}
+
+	if w.visiting == nil {
+		w.visiting = make(map[visit]bool)
+	}
+	w.visiting[key] = true
+	defer delete(w.visiting, key)
+
+	return w.validateValue(v.Elem(), rules)
+}
+
+// applyChecks reports errors of checks. An error wrapping ErrInvalidTag (returned by a custom rule
+// for a malformed argument) is a programmer error, it is returned.
+func (w *walker) applyChecks(checks []ruleCheck, v reflect.Value) error {
+	for _, c := range checks {
+		err := c.check(v)
+		if errors.Is(err, ErrInvalidTag) {
+			return fmt.Errorf("field %s: %w", w.path, err)
+		}
+		if err != nil {
+			w.fail(c.rule, c.param, err)
+		}
+	}
+
+	return nil
+}
+
+// applyCross reports errors of checks comparing v with sibling fields, nil siblings are not compared.
+func (w *walker) applyCross(parent reflect.Value, checks []crossCheck, v reflect.Value) {
+	for i := range checks {
+		c := &checks[i]
+		other := sibling(parent, c.index)
+		if !other.IsValid() {
+			continue
+		}
+		if err := c.check(v, other); err != nil {
+			w.fail(c.rule, c.param, err)
+		}
+	}
+}
+
+func (w *walker) fail(rule, param string, err error) {
+	w.errs = append(w.errs, ValidationError{Field: w.path.String(), Rule: rule, Param: param, Err: err})
+}
+
+// pathElem is a field name, a slice index or a map key.
+type pathElem struct {
+	field string
+	index int
+	key   reflect.Value
+}
+
+// path is a path to a value: Name, Address.Zip, Phones[2], Labels[key].
+type path []pathElem
+
+func (p path) String() string {
+	var sb strings.Builder
+	for _, e := range p {
+		switch {
+		case e.field != "":
+			if sb.Len() > 0 {
+				sb.WriteByte('.')
+			}
+			sb.WriteString(e.field)
+		case e.key.IsValid():
+			fmt.Fprintf(&sb, "[%v]", e.key)
+		default:
+			sb.WriteString("[" + strconv.Itoa(e.index) + "]")
+		}
+	}
+
+	return sb.String()
+}
+
+// isEmpty reports whether v is a zero value, an empty slice or an empty map.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+// indirect returns the value under pointers, an invalid value for nil pointers.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+
+	return v
+}
+
+// sortedKeys returns keys of the map in a stable order, so errors are reported in the same order.
+// Numbers and strings are compared as they are, keys of other kinds by their text.
+func sortedKeys(m reflect.Value) []reflect.Value {
+	keys := m.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		switch a.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return a.Int() < b.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return a.Uint() < b.Uint()
+		case reflect.Float32, reflect.Float64:
+			return a.Float() < b.Float()
+		case reflect.String:
+			return a.String() < b.String()
+		default:
+			return fmt.Sprint(a) < fmt.Sprint(b)
+		}
+	})
+
+	return keys
+}
@@ -0,0 +1,587 @@
+// Code generated by validgen -type User,App,Token,Response,Customer,Event,Signup,Booking,Node; DO NOT EDIT.
+
+package hw09structvalidator
+
+import (
+	"cmp"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	validgenRegexp0 = regexp.MustCompile("^\\w+@\\w+\\.\\w+$")
+	validgenTime1   = validgenParseTime("2006-01-02", "2020-01-01")
+	validgenTime2   = validgenParseTime("2006-01-02T15:04:05Z07:00", "2030-01-01T00:00:00Z")
+	validgenRegexp3 = regexp.MustCompile("^\\d{6}$")
+)
+
+// Validate validates x like Validate(x) does, without reflection.
+func (x User) Validate() error {
+	w := validgenWalker{path: make([]validgenElem, 0, 8)}
+	x.validgen(&w)
+
+	return w.result()
+}
+
+// Validate validates x like Validate(x) does, without reflection.
+func (x App) Validate() error {
+	w := validgenWalker{path: make([]validgenElem, 0, 8)}
+	x.validgen(&w)
+
+	return w.result()
+}
+
+// Validate validates x like Validate(x) does, without reflection.
+func (x Token) Validate() error {
+	w := validgenWalker{path: make([]validgenElem, 0, 8)}
+	x.validgen(&w)
+
+	return w.result()
+}
+
+// Validate validates x like Validate(x) does, without reflection.
+func (x Response) Validate() error {
+	w := validgenWalker{path: make([]validgenElem, 0, 8)}
+	x.validgen(&w)
+
+	return w.result()
+}
+
+// Validate validates x like Validate(x) does, without reflection.
+func (x Customer) Validate() error {
+	w := validgenWalker{path: make([]validgenElem, 0, 8)}
+	x.validgen(&w)
+
+	return w.result()
+}
+
+// Validate validates x like Validate(x) does, without reflection.
+func (x Event) Validate() error {
+	w := validgenWalker{path: make([]validgenElem, 0, 8)}
+	x.validgen(&w)
+
+	return w.result()
+}
+
+// Validate validates x like Validate(x) does, without reflection.
+func (x Signup) Validate() error {
+	w := validgenWalker{path: make([]validgenElem, 0, 8)}
+	x.validgen(&w)
+
+	return w.result()
+}
+
+// Validate validates x like Validate(x) does, without reflection.
+func (x Booking) Validate() error {
+	w := validgenWalker{path: make([]validgenElem, 0, 8)}
+	x.validgen(&w)
+
+	return w.result()
+}
+
+// Validate validates x like Validate(x) does, without reflection.
+func (x Node) Validate() error {
+	w := validgenWalker{path: make([]validgenElem, 0, 8)}
+	x.validgen(&w)
+
+	return w.result()
+}
+
+func (x *User) validgen(w *validgenWalker) {
+	w.path = append(w.path, validgenElem{field: "ID"})
+	s1 := string(x.ID)
+	if l := utf8.RuneCountInString(s1); l != 36 {
+		w.fail("len", "36", fmt.Errorf("%w: expected %d, got %d", ErrLen, 36, l))
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Age"})
+	n2 := int64(x.Age)
+	if n2 < 18 {
+		w.fail("min", "18", fmt.Errorf("%d %w %d", n2, ErrMin, int64(18)))
+	}
+	if n2 > 50 {
+		w.fail("max", "50", fmt.Errorf("%d %w %d", n2, ErrMax, int64(50)))
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Email"})
+	s3 := string(x.Email)
+	if !validgenRegexp0.MatchString(s3) {
+		w.fail("regexp", "^\\w+@\\w+\\.\\w+$", fmt.Errorf("%q %w %s", s3, ErrRegexp, "^\\w+@\\w+\\.\\w+$"))
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Role"})
+	s4 := string(x.Role)
+	switch s4 {
+	case "admin", "stuff":
+	default:
+		w.fail("in", "admin,stuff", fmt.Errorf("%q %w %s", s4, ErrIn, "{admin,stuff}"))
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Phones"})
+	for i5 := range x.Phones {
+		w.path = append(w.path, validgenElem{index: i5})
+		s6 := string(x.Phones[i5])
+		if l := utf8.RuneCountInString(s6); l != 11 {
+			w.fail("len", "11", fmt.Errorf("%w: expected %d, got %d", ErrLen, 11, l))
+		}
+		w.path = w.path[:len(w.path)-1]
+	}
+	w.path = w.path[:len(w.path)-1]
+}
+
+func (x *App) validgen(w *validgenWalker) {
+	w.path = append(w.path, validgenElem{field: "Version"})
+	s7 := string(x.Version)
+	if l := utf8.RuneCountInString(s7); l != 5 {
+		w.fail("len", "5", fmt.Errorf("%w: expected %d, got %d", ErrLen, 5, l))
+	}
+	w.path = w.path[:len(w.path)-1]
+}
+
+func (x *Token) validgen(w *validgenWalker) {
+}
+
+func (x *Response) validgen(w *validgenWalker) {
+	w.path = append(w.path, validgenElem{field: "Code"})
+	n8 := int64(x.Code)
+	switch n8 {
+	case 200, 404, 500:
+	default:
+		w.fail("in", "200,404,500", fmt.Errorf("%d %w {%s}", n8, ErrIn, "200,404,500"))
+	}
+	w.path = w.path[:len(w.path)-1]
+}
+
+func (x *Customer) validgen(w *validgenWalker) {
+	w.path = append(w.path, validgenElem{field: "Name"})
+	s9 := string(x.Name)
+	if l := utf8.RuneCountInString(s9); l != 4 {
+		w.fail("len", "4", fmt.Errorf("%w: expected %d, got %d", ErrLen, 4, l))
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Address"})
+	x.Address.validgen(w)
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Billing"})
+	if p10 := x.Billing; p10 != nil {
+		if w.enter(p10) {
+			(*p10).validgen(w)
+			w.leave(p10)
+		}
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Shipping"})
+	for i11 := range x.Shipping {
+		w.path = append(w.path, validgenElem{index: i11})
+		x.Shipping[i11].validgen(w)
+		w.path = w.path[:len(w.path)-1]
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Offices"})
+	keys12, names13 := validgenSortedOrderedKeys(x.Offices)
+	for i14, k15 := range keys12 {
+		w.path = append(w.path, validgenElem{key: names13[i14], isKey: true})
+		v16 := x.Offices[k15]
+		if p17 := v16; p17 != nil {
+			if w.enter(p17) {
+				(*p17).validgen(w)
+				w.leave(p17)
+			}
+		}
+		w.path = w.path[:len(w.path)-1]
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Scores"})
+	keys18, names19 := validgenSortedOrderedKeys(x.Scores)
+	for i20, k21 := range keys18 {
+		w.path = append(w.path, validgenElem{key: names19[i20], isKey: true})
+		v22 := x.Scores[k21]
+		n23 := int64(v22)
+		if n23 < 0 {
+			w.fail("min", "0", fmt.Errorf("%d %w %d", n23, ErrMin, int64(0)))
+		}
+		w.path = w.path[:len(w.path)-1]
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Levels"})
+	keys24, names25 := validgenSortedOrderedKeys(x.Levels)
+	for i26, k27 := range keys24 {
+		w.path = append(w.path, validgenElem{key: names25[i26], isKey: true})
+		v28 := x.Levels[k27]
+		n29 := int64(v28)
+		if n29 < 0 {
+			w.fail("min", "0", fmt.Errorf("%d %w %d", n29, ErrMin, int64(0)))
+		}
+		w.path = w.path[:len(w.path)-1]
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Codes"})
+	for i30 := range x.Codes {
+		w.path = append(w.path, validgenElem{index: i30})
+		if p31 := x.Codes[i30]; p31 != nil {
+			n32 := int64((*p31))
+			if n32 > 10 {
+				w.fail("max", "10", fmt.Errorf("%d %w %d", n32, ErrMax, int64(10)))
+			}
+		}
+		w.path = w.path[:len(w.path)-1]
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Responses"})
+	for i33 := range x.Responses {
+		w.path = append(w.path, validgenElem{index: i33})
+		for i34 := range x.Responses[i33] {
+			w.path = append(w.path, validgenElem{index: i34})
+			x.Responses[i33][i34].validgen(w)
+			w.path = w.path[:len(w.path)-1]
+		}
+		w.path = w.path[:len(w.path)-1]
+	}
+	w.path = w.path[:len(w.path)-1]
+}
+
+func (x *Event) validgen(w *validgenWalker) {
+	w.path = append(w.path, validgenElem{field: "ID"})
+	if x.ID == "" {
+		w.fail("required", "", ErrRequired)
+	} else {
+		s35 := string(x.ID)
+		if !validgenUUID.MatchString(s35) {
+			w.fail("uuid", "", fmt.Errorf("%q %w", s35, ErrUUID))
+		}
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Owner"})
+	if x.Owner == nil {
+		w.fail("required", "", ErrRequired)
+	} else {
+		if p36 := x.Owner; p36 != nil {
+			s37 := string((*p36))
+			if !validgenIsEmail(s37) {
+				w.fail("email", "", fmt.Errorf("%q %w", s37, ErrEmail))
+			}
+		}
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Link"})
+	s38 := string(x.Link)
+	if !validgenIsURL(s38) {
+		w.fail("url", "", fmt.Errorf("%q %w", s38, ErrURL))
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Hosts"})
+	if l := len(x.Hosts); l < 1 {
+		w.fail("min_len", "1", fmt.Errorf("%w: %d < %d", ErrMinLen, l, 1))
+	}
+	if l := len(x.Hosts); l > 2 {
+		w.fail("max_len", "2", fmt.Errorf("%w: %d > %d", ErrMaxLen, l, 2))
+	}
+	for i39 := range x.Hosts {
+		w.path = append(w.path, validgenElem{index: i39})
+		s40 := string(x.Hosts[i39])
+		if net.ParseIP(s40) == nil {
+			w.fail("ip", "", fmt.Errorf("%q %w", s40, ErrIP))
+		}
+		w.path = w.path[:len(w.path)-1]
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Status"})
+	n41 := int64(x.Status)
+	switch n41 {
+	case 1, 2, 3:
+	default:
+		w.fail("oneof", "1 2 3", fmt.Errorf("%d %w {%s}", n41, ErrIn, "1 2 3"))
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Price"})
+	f42 := float64(x.Price)
+	if !(f42 > float64(0)) {
+		w.fail("gt", "0", fmt.Errorf("%g %w %g", f42, ErrGt, float64(0)))
+	}
+	if !(f42 <= float64(1000)) {
+		w.fail("lte", "1000", fmt.Errorf("%g %w %g", f42, ErrLte, float64(1000)))
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Discount"})
+	f43 := float64(x.Discount)
+	if !(f43 >= float64(0)) {
+		w.fail("gte", "0", fmt.Errorf("%g %w %g", f43, ErrGte, float64(0)))
+	}
+	if !(f43 < float64(1)) {
+		w.fail("lt", "1", fmt.Errorf("%g %w %g", f43, ErrLt, float64(1)))
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Code"})
+	s44 := string(x.Code)
+	if !strings.HasPrefix(s44, "EV-") {
+		w.fail("prefix", "EV-", fmt.Errorf("%q %w %s", s44, ErrPrefix, "\"EV-\""))
+	}
+	if !strings.HasSuffix(s44, "-X") {
+		w.fail("suffix", "-X", fmt.Errorf("%q %w %s", s44, ErrSuffix, "\"-X\""))
+	}
+	if !strings.Contains(s44, "2024") {
+		w.fail("contains", "2024", fmt.Errorf("%q %w %s", s44, ErrContains, "\"2024\""))
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Start"})
+	t45 := x.Start
+	if !t45.After(validgenTime1) {
+		w.fail("after", "2020-01-01", fmt.Errorf("%s %w %s", t45.Format(time.RFC3339), ErrAfter, validgenTime1.Format(time.RFC3339)))
+	}
+	if !t45.Before(validgenTime2) {
+		w.fail("before", "2030-01-01T00:00:00Z", fmt.Errorf("%s %w %s", t45.Format(time.RFC3339), ErrBefore, validgenTime2.Format(time.RFC3339)))
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Tags"})
+	if l := len(x.Tags); l > 1 {
+		w.fail("max_len", "1", fmt.Errorf("%w: %d > %d", ErrMaxLen, l, 1))
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Title"})
+	if l := utf8.RuneCountInString(string(x.Title)); l < 2 {
+		w.fail("min_len", "2", fmt.Errorf("%w: %d < %d", ErrMinLen, l, 2))
+	}
+	if l := utf8.RuneCountInString(string(x.Title)); l > 5 {
+		w.fail("max_len", "5", fmt.Errorf("%w: %d > %d", ErrMaxLen, l, 5))
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Speakers"})
+	if len(x.Speakers) == 0 {
+		w.fail("required", "", ErrRequired)
+	} else {
+		for i46 := range x.Speakers {
+			w.path = append(w.path, validgenElem{index: i46})
+			x.Speakers[i46].validgen(w)
+			w.path = w.path[:len(w.path)-1]
+		}
+	}
+	w.path = w.path[:len(w.path)-1]
+}
+
+func (x *Signup) validgen(w *validgenWalker) {
+	w.path = append(w.path, validgenElem{field: "Role"})
+	s47 := string(x.Role)
+	switch s47 {
+	case "user", "admin":
+	default:
+		w.fail("in", "user,admin", fmt.Errorf("%q %w %s", s47, ErrIn, "{user,admin}"))
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Password"})
+	if l := utf8.RuneCountInString(string(x.Password)); l < 8 {
+		w.fail("min_len", "8", fmt.Errorf("%w: %d < %d", ErrMinLen, l, 8))
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Confirm"})
+	if !(cmp.Compare(string(x.Confirm), string(x.Password)) == 0) {
+		w.fail("eqfield", "Password", fmt.Errorf("%w %s", ErrEqField, "Password"))
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Login"})
+	if x.Login == "" {
+		w.fail("required", "", ErrRequired)
+	} else {
+		if !(cmp.Compare(string(x.Login), string(x.Password)) != 0) {
+			w.fail("nefield", "Password", fmt.Errorf("%w %s", ErrNeField, "Password"))
+		}
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Phone"})
+	if x.Phone == "" && string(x.Role) == "admin" {
+		w.fail("required_if", "Role admin", fmt.Errorf("%w if %s is %s", ErrRequired, "Role", "admin"))
+	} else {
+		s48 := string(x.Phone)
+		if l := utf8.RuneCountInString(s48); l != 12 {
+			w.fail("len", "12", fmt.Errorf("%w: expected %d, got %d", ErrLen, 12, l))
+		}
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Seats"})
+	if x.Seats == 0 && bool(x.Team) == true {
+		w.fail("required_if", "Team true", fmt.Errorf("%w if %s is %s", ErrRequired, "Team", "true"))
+	} else {
+	}
+	w.path = w.path[:len(w.path)-1]
+}
+
+func (x *Booking) validgen(w *validgenWalker) {
+	w.path = append(w.path, validgenElem{field: "End"})
+	if x.End != nil {
+		if !((*x.End).Compare(x.Start) > 0) {
+			w.fail("gtfield", "Start", fmt.Errorf("%w %s", ErrGt, "Start"))
+		}
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "MinSeats"})
+	if !(cmp.Compare(int64(x.MinSeats), int64(x.MaxSeats)) <= 0) {
+		w.fail("ltefield", "MaxSeats", fmt.Errorf("%w %s", ErrLte, "MaxSeats"))
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "MaxSeats"})
+	if !(cmp.Compare(int64(x.MaxSeats), int64(x.Seats)) < 0) {
+		w.fail("ltfield", "Seats", fmt.Errorf("%w %s", ErrLt, "Seats"))
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Price"})
+	if x.Price != nil {
+		if x.Deposit != nil && !(cmp.Compare(float64((*x.Price)), float64((*x.Deposit))) >= 0) {
+			w.fail("gtefield", "Deposit", fmt.Errorf("%w %s", ErrGte, "Deposit"))
+		}
+	}
+	w.path = w.path[:len(w.path)-1]
+}
+
+func (x *Node) validgen(w *validgenWalker) {
+	w.path = append(w.path, validgenElem{field: "Name"})
+	s49 := string(x.Name)
+	if l := utf8.RuneCountInString(s49); l != 1 {
+		w.fail("len", "1", fmt.Errorf("%w: expected %d, got %d", ErrLen, 1, l))
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Children"})
+	for i50 := range x.Children {
+		w.path = append(w.path, validgenElem{index: i50})
+		if p51 := x.Children[i50]; p51 != nil {
+			if w.enter(p51) {
+				(*p51).validgen(w)
+				w.leave(p51)
+			}
+		}
+		w.path = w.path[:len(w.path)-1]
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Parent"})
+	if p52 := x.Parent; p52 != nil {
+		if w.enter(p52) {
+			(*p52).validgen(w)
+			w.leave(p52)
+		}
+	}
+	w.path = w.path[:len(w.path)-1]
+}
+
+func (x *Address) validgen(w *validgenWalker) {
+	w.path = append(w.path, validgenElem{field: "City"})
+	s53 := string(x.City)
+	switch s53 {
+	case "Moscow", "Kazan":
+	default:
+		w.fail("in", "Moscow,Kazan", fmt.Errorf("%q %w %s", s53, ErrIn, "{Moscow,Kazan}"))
+	}
+	w.path = w.path[:len(w.path)-1]
+	w.path = append(w.path, validgenElem{field: "Zip"})
+	s54 := string(x.Zip)
+	if !validgenRegexp3.MatchString(s54) {
+		w.fail("regexp", "^\\d{6}$", fmt.Errorf("%q %w %s", s54, ErrRegexp, "^\\d{6}$"))
+	}
+	w.path = w.path[:len(w.path)-1]
+}
+
+// validgenElem is a field name, a slice index or a map key in a path to a value.
+type validgenElem struct {
+	field string
+	index int
+	key   string
+	isKey bool
+}
+
+// validgenWalker collects errors, the path to the current value is built only when an error is reported.
+type validgenWalker struct {
+	path     []validgenElem
+	errs     ValidationErrors
+	visiting map[any]bool
+}
+
+func (w *validgenWalker) fail(rule, param string, err error) {
+	var sb strings.Builder
+	for _, e := range w.path {
+		switch {
+		case e.field != "":
+			if sb.Len() > 0 {
+				sb.WriteByte('.')
+			}
+			sb.WriteString(e.field)
+		case e.isKey:
+			sb.WriteString("[" + e.key + "]")
+		default:
+			sb.WriteString("[" + strconv.Itoa(e.index) + "]")
+		}
+	}
+
+	w.errs = append(w.errs, ValidationError{Field: sb.String(), Rule: rule, Param: param, Err: err})
+}
+
+// enter marks a pointed struct as being validated, a pointer cycle is not followed again.
+func (w *validgenWalker) enter(p any) bool {
+	if w.visiting[p] {
+		return false
+	}
+
+	if w.visiting == nil {
+		w.visiting = make(map[any]bool)
+	}
+	w.visiting[p] = true
+
+	return true
+}
+
+func (w *validgenWalker) leave(p any) {
+	delete(w.visiting, p)
+}
+
+func (w *validgenWalker) result() error {
+	if len(w.errs) > 0 {
+		return w.errs
+	}
+
+	return nil
+}
+
+// validgenSortedOrderedKeys returns keys of the map and their names, numbers and strings are compared as they are.
+func validgenSortedOrderedKeys[M ~map[K]V, K cmp.Ordered, V any](m M) ([]K, []string) {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = fmt.Sprint(k)
+	}
+
+	return keys, names
+}
+
+// validgenIsEmail accepts a bare address such as user@example.com.
+func validgenIsEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Name == "" && addr.Address == s
+}
+
+// validgenIsURL accepts absolute URLs with a host.
+func validgenIsURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+var validgenUUID = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func validgenParseTime(layout, value string) time.Time {
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
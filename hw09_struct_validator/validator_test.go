@@ -2,7 +2,12 @@ package hw09structvalidator
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"sync"
 	"testing"
 )
 
@@ -34,18 +39,119 @@ type (
 		Code int    `validate:"in:200,404,500"`
 		Body string `json:"omitempty"`
 	}
+
+	Address struct {
+		City string `validate:"in:Moscow,Kazan"`
+		Zip  string `validate:"regexp:^\\d{6}$"`
+	}
+
+	Customer struct {
+		Name      string              `validate:"len:4"`
+		Address   Address             `validate:"nested"`
+		Billing   *Address            `validate:"nested"`
+		Shipping  []Address           `validate:"nested"`
+		Offices   map[string]*Address `validate:"nested"`
+		Scores    map[string]int      `validate:"min:0"`
+		Levels    map[int]int         `validate:"min:0"`
+		Codes     []*int              `validate:"max:10"`
+		Responses [][]Response        `validate:"nested"`
+		Untagged  Address
+	}
 )
 
+var validUser = User{
+	ID:     "3f2b1c4e-8d6a-4e7b-9c1d-2a5f6e8b7c9d",
+	Name:   "John",
+	Age:    30,
+	Email:  "john@example.com",
+	Role:   "admin",
+	Phones: []string{"79001234567", "79007654321"},
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
 func TestValidate(t *testing.T) {
 	tests := []struct {
 		in          interface{}
 		expectedErr error
 	}{
+		{in: validUser},
+		{in: &validUser},
+		{in: App{Version: "1.0.0"}},
+		{in: Token{Header: []byte("h")}},
+		{in: Response{Code: 404, Body: "not found"}},
+		{
+			in: User{
+				ID:     "short",
+				Age:    17,
+				Email:  "not an email",
+				Role:   "guest",
+				Phones: []string{"79001234567", "123", "79007654321", "+79007654321"},
+			},
+			expectedErr: ValidationErrors{
+				{Field: "ID", Err: ErrLen},
+				{Field: "Age", Err: ErrMin},
+				{Field: "Email", Err: ErrRegexp},
+				{Field: "Role", Err: ErrIn},
+				{Field: "Phones[1]", Err: ErrLen},
+				{Field: "Phones[3]", Err: ErrLen},
+			},
+		},
+		{
+			in:          User{ID: validUser.ID, Age: 51, Email: validUser.Email, Role: "stuff"},
+			expectedErr: ValidationErrors{{Field: "Age", Err: ErrMax}},
+		},
+		{
+			in:          App{Version: "1.0"},
+			expectedErr: ValidationErrors{{Field: "Version", Err: ErrLen}},
+		},
 		{
-			// Place your code here.
+			in:          Response{Code: 201},
+			expectedErr: ValidationErrors{{Field: "Code", Err: ErrIn}},
+		},
+		{
+			in: Customer{
+				Name:     "Anna",
+				Address:  Address{City: "Moscow", Zip: "101000"},
+				Shipping: []Address{{City: "Kazan", Zip: "420000"}},
+				Offices:  map[string]*Address{"main": {City: "Kazan", Zip: "420001"}, "closed": nil},
+				Scores:   map[string]int{"a": 1},
+				Codes:    []*int{intPtr(1), nil},
+			},
+		},
+		{
+			in: Customer{
+				Name:     "Anna",
+				Address:  Address{City: "Paris", Zip: "75001"},
+				Billing:  &Address{City: "Moscow", Zip: "1"},
+				Shipping: []Address{{City: "Kazan", Zip: "420000"}, {City: "Omsk", Zip: "644000"}},
+				Offices: map[string]*Address{
+					"north": {City: "Kazan", Zip: "x"},
+					"main":  {City: "Tver", Zip: "170000"},
+				},
+				Scores:    map[string]int{"b": -1, "a": -2, "c": 0},
+				Levels:    map[int]int{10: -1, 2: -1, 1: 0},
+				Codes:     []*int{intPtr(11), nil, intPtr(10)},
+				Responses: [][]Response{{{Code: 200}}, {{Code: 200}, {Code: 302}}},
+				Untagged:  Address{City: "ignored"},
+			},
+			expectedErr: ValidationErrors{
+				{Field: "Address.City", Err: ErrIn},
+				{Field: "Address.Zip", Err: ErrRegexp},
+				{Field: "Billing.Zip", Err: ErrRegexp},
+				{Field: "Shipping[1].City", Err: ErrIn},
+				{Field: "Offices[main].City", Err: ErrIn},
+				{Field: "Offices[north].Zip", Err: ErrRegexp},
+				{Field: "Scores[a]", Err: ErrMin},
+				{Field: "Scores[b]", Err: ErrMin},
+				{Field: "Levels[2]", Err: ErrMin},
+				{Field: "Levels[10]", Err: ErrMin},
+				{Field: "Codes[0]", Err: ErrMax},
+				{Field: "Responses[1][1].Code", Err: ErrIn},
+			},
 		},
-		// ...
-		// Place your code here.
 	}
 
 	for i, tt := range tests {
@@ -53,8 +159,308 @@ func TestValidate(t *testing.T) {
 			tt := tt
 			t.Parallel()
 
-			// Place your code here.
-			_ = tt
+			err := Validate(tt.in)
+			if tt.expectedErr == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			requireValidationErrors(t, tt.expectedErr.(ValidationErrors), err)
 		})
 	}
 }
+
+func TestValidateProgrammerErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		in          interface{}
+		expectedErr error
+	}{
+		{name: "nil", in: nil, expectedErr: ErrNotStruct},
+		{name: "string", in: "user", expectedErr: ErrNotStruct},
+		{name: "nil pointer", in: (*User)(nil), expectedErr: ErrNotStruct},
+		{name: "slice of structs", in: []User{validUser}, expectedErr: ErrNotStruct},
+		{
+			name: "unknown rule",
+			in: struct {
+				Name string `validate:"size:5"`
+			}{},
+			expectedErr: ErrUnknownRule,
+		},
+		{
+			name: "rule of another type",
+			in: struct {
+				Age int `validate:"regexp:\\d+"`
+			}{},
+			expectedErr: ErrUnknownRule,
+		},
+		{
+			name: "malformed rule",
+			in: struct {
+				Name string `validate:"len"`
+			}{},
+			expectedErr: ErrInvalidTag,
+		},
+		{
+			name: "invalid length",
+			in: struct {
+				Name string `validate:"len:five"`
+			}{},
+			expectedErr: ErrInvalidTag,
+		},
+		{
+			name: "invalid regexp",
+			in: struct {
+				Name string `validate:"regexp:[a-"`
+			}{},
+			expectedErr: ErrInvalidTag,
+		},
+		{
+			name: "invalid number in set",
+			in: struct {
+				Code int `validate:"in:200,ok"`
+			}{},
+			expectedErr: ErrInvalidTag,
+		},
+		{
+			name: "nested of not struct",
+			in: struct {
+				Name string `validate:"nested"`
+			}{},
+			expectedErr: ErrInvalidTag,
+		},
+		{
+			name: "struct without nested",
+			in: struct {
+				Address Address `validate:"required"`
+			}{},
+			expectedErr: ErrInvalidTag,
+		},
+		{
+			name: "unsupported type",
+			in: struct {
+				Enabled bool `validate:"in:true"`
+			}{},
+			expectedErr: ErrUnsupportedType,
+		},
+		{
+			name: "in nested struct",
+			in: struct {
+				Inner struct {
+					Name string `validate:"len:x"`
+				} `validate:"nested"`
+			}{},
+			expectedErr: ErrInvalidTag,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt := tt
+			t.Parallel()
+
+			err := Validate(tt.in)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+			}
+
+			var validationErrors ValidationErrors
+			if errors.As(err, &validationErrors) {
+				t.Fatalf("programmer error is reported as ValidationErrors: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidationErrorsError(t *testing.T) {
+	err := Validate(User{ID: validUser.ID, Age: 10, Email: validUser.Email, Role: "admin", Phones: []string{"1"}})
+
+	expected := `Age must be at least 18; Phones[0] must be exactly 11 characters long`
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected %q, got %v", expected, err)
+	}
+}
+
+var (
+	ErrPhone     = errors.New("is not a russian phone number")
+	ErrINN       = errors.New("is not a valid INN")
+	ErrDivisible = errors.New("is not divisible")
+	phoneRe      = regexp.MustCompile(`^\+7\d{10}$`)
+)
+
+// phoneRule accepts strings only, so it can be used with nested values and slices of strings.
+func phoneRule(v reflect.Value, _ string) error {
+	if !phoneRe.MatchString(v.String()) {
+		return fmt.Errorf("%q %w", v.String(), ErrPhone)
+	}
+	return nil
+}
+
+// innRule checks the length and the checksum of a 10 digits INN of organization.
+func innRule(v reflect.Value, _ string) error {
+	s := v.String()
+	if len(s) != 10 {
+		return fmt.Errorf("%w: expected 10 digits", ErrINN)
+	}
+
+	sum := 0
+	for i, weight := range []int{2, 4, 10, 3, 5, 9, 4, 6, 8} {
+		d, err := strconv.Atoi(s[i : i+1])
+		if err != nil {
+			return fmt.Errorf("%w: expected digits", ErrINN)
+		}
+		sum += d * weight
+	}
+
+	if strconv.Itoa(sum%11%10) != s[9:] {
+		return fmt.Errorf("%w: invalid checksum", ErrINN)
+	}
+	return nil
+}
+
+func divisibleRule(v reflect.Value, arg string) error {
+	n, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || n == 0 {
+		return fmt.Errorf("%w: divisor %q", ErrInvalidTag, arg)
+	}
+
+	if v.Int()%n != 0 {
+		return fmt.Errorf("%d %w by %d", v.Int(), ErrDivisible, n)
+	}
+	return nil
+}
+
+type Company struct {
+	INN    string   `validate:"required|inn"`
+	Phones []string `validate:"min_len:1|phone_ru|len:12"`
+	Seats  int      `validate:"min:1|divisible:4"`
+}
+
+func TestValidatorRegisterRule(t *testing.T) {
+	v := New()
+	for name, fn := range map[string]RuleFunc{"inn": innRule, "phone_ru": phoneRule, "divisible": divisibleRule} {
+		if err := v.RegisterRule(name, fn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := v.Validate(Company{INN: "7707083893", Phones: []string{"+79001234567"}, Seats: 8}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := v.Validate(Company{INN: "7707083890", Phones: []string{"+79001234567", "89001234567"}, Seats: 6})
+	requireValidationErrors(t, ValidationErrors{
+		{Field: "INN", Err: ErrINN},
+		{Field: "Phones[1]", Err: ErrPhone},
+		{Field: "Phones[1]", Err: ErrLen},
+		{Field: "Seats", Err: ErrDivisible},
+	}, err)
+
+	err = v.Validate(Company{})
+	requireValidationErrors(t, ValidationErrors{
+		{Field: "INN", Err: ErrRequired},
+		{Field: "Phones", Err: ErrMinLen},
+		{Field: "Seats", Err: ErrMin},
+	}, err)
+
+	t.Run("malformed argument", func(t *testing.T) {
+		err := v.Validate(struct {
+			N int `validate:"divisible:0"`
+		}{N: 1})
+		if !errors.Is(err, ErrInvalidTag) {
+			t.Fatalf("expected programmer error, got %v", err)
+		}
+	})
+
+	t.Run("other validators", func(t *testing.T) {
+		err := New().Validate(Company{})
+		if !errors.Is(err, ErrUnknownRule) {
+			t.Fatalf("rules of another validator must be unknown, got %v", err)
+		}
+
+		err = Validate(Company{})
+		if !errors.Is(err, ErrUnknownRule) {
+			t.Fatalf("rules of another validator must be unknown, got %v", err)
+		}
+	})
+
+	t.Run("invalid names", func(t *testing.T) {
+		for _, name := range []string{"", "len", "required", "a:b", "a|b", "a b"} {
+			if err := v.RegisterRule(name, phoneRule); !errors.Is(err, ErrInvalidRuleName) {
+				t.Errorf("%q: expected ErrInvalidRuleName, got %v", name, err)
+			}
+		}
+
+		if err := v.RegisterRule("nil", nil); !errors.Is(err, ErrInvalidRuleName) {
+			t.Errorf("expected ErrInvalidRuleName for nil function, got %v", err)
+		}
+	})
+}
+
+func TestRegisterRuleDefault(t *testing.T) {
+	type Phone struct {
+		Number string `validate:"default_phone_ru"`
+	}
+
+	if err := RegisterRule("default_phone_ru", phoneRule); err != nil {
+		t.Fatal(err)
+	}
+
+	requireValidationErrors(t, ValidationErrors{{Field: "Number", Err: ErrPhone}}, Validate(Phone{Number: "123"}))
+}
+
+func TestValidatorConcurrency(t *testing.T) {
+	v := New()
+	if err := v.RegisterRule("phone_ru", phoneRule); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				err := v.Validate(Company{Phones: []string{"1"}})
+				if !errors.Is(err, ErrPhone) && !errors.Is(err, ErrUnknownRule) {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+			}
+		}()
+
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = v.RegisterRule("inn", innRule)
+				_ = v.RegisterRule(fmt.Sprintf("rule_%d_%d", i, j), divisibleRule)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+// requireValidationErrors checks that err is ValidationErrors with the expected fields
+// and errors wrapping the expected ones.
+func requireValidationErrors(t *testing.T, expected ValidationErrors, err error) {
+	t.Helper()
+
+	var actual ValidationErrors
+	if !errors.As(err, &actual) {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %d errors, got %d: %v", len(expected), len(actual), actual)
+	}
+
+	for i := range expected {
+		if actual[i].Field != expected[i].Field || !errors.Is(actual[i].Err, expected[i].Err) {
+			t.Errorf("error %d: expected %s: %v, got %s: %v",
+				i, expected[i].Field, expected[i].Err, actual[i].Field, actual[i].Err)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package hw09structvalidator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+//go:generate go run ./cmd/validgen -type User,App,Token,Response,Customer,Event,Signup,Booking,Node -output validator_gen_test.go
+
+// generated is a type with a Validate method generated by validgen.
+type generated interface {
+	Validate() error
+}
+
+// sentinels are validation errors compared with errors.Is.
+var sentinels = []error{
+	ErrRequired, ErrLen, ErrRegexp, ErrIn, ErrMin, ErrMax, ErrEmail, ErrUUID, ErrURL, ErrIP,
+	ErrGt, ErrGte, ErrLt, ErrLte, ErrMinLen, ErrMaxLen, ErrPrefix, ErrSuffix, ErrContains,
+	ErrBefore, ErrAfter, ErrEqField, ErrNeField,
+}
+
+func conformanceValues() []generated {
+	root := &Node{Name: "r"}
+	root.Children = []*Node{{Name: "a", Parent: root}, {Name: "bb", Children: []*Node{{Name: ""}}}}
+
+	start := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	end, before := start.Add(time.Hour), start.Add(-time.Hour)
+	price, deposit := 10.0, 20.0
+	owner := "not an email"
+
+	event := validEvent()
+	invalidEvent := Event{
+		ID:       "not-a-uuid",
+		Owner:    &owner,
+		Link:     "/relative",
+		Hosts:    []string{"10.0.0.1", "host", "::1"},
+		Status:   4,
+		Price:    math.NaN(),
+		Discount: 1,
+		Code:     "2024",
+		Start:    time.Date(2031, 1, 1, 0, 0, 0, 0, time.FixedZone("MSK", 3*60*60)),
+		Tags:     map[string]string{"a": "1", "b": "2"},
+		Title:    "Конференция",
+		Speakers: []Address{{City: "Omsk", Zip: "1"}},
+	}
+
+	return []generated{
+		validUser,
+		User{ID: "short", Age: 17, Email: "not an email", Role: "guest", Phones: []string{"79001234567", "123", "ё"}},
+		User{ID: validUser.ID, Age: 51, Email: validUser.Email, Role: "stuff"},
+		App{Version: "1.0.0"},
+		App{Version: "1.0"},
+		Token{Header: []byte("h")},
+		Response{Code: 404},
+		Response{Code: 201},
+		Customer{
+			Name:     "Anna",
+			Address:  Address{City: "Moscow", Zip: "101000"},
+			Shipping: []Address{{City: "Kazan", Zip: "420000"}},
+			Offices:  map[string]*Address{"main": {City: "Kazan", Zip: "420001"}, "closed": nil},
+			Scores:   map[string]int{"a": 1},
+			Codes:    []*int{intPtr(1), nil},
+		},
+		Customer{
+			Name:     "Анна",
+			Address:  Address{City: "Paris", Zip: "75001"},
+			Billing:  &Address{City: "Moscow", Zip: "1"},
+			Shipping: []Address{{City: "Kazan", Zip: "420000"}, {City: "Omsk", Zip: "644000"}},
+			Offices: map[string]*Address{
+				"north": {City: "Kazan", Zip: "x"},
+				"main":  {City: "Tver", Zip: "170000"},
+			},
+			Scores:    map[string]int{"b": -1, "a": -2, "c": 0},
+			Levels:    map[int]int{10: -1, 2: -1, 1: 0},
+			Codes:     []*int{intPtr(11), nil, intPtr(10)},
+			Responses: [][]Response{{{Code: 200}}, {{Code: 200}, {Code: 302}}},
+		},
+		event,
+		invalidEvent,
+		Event{Price: -1, Discount: -0.5},
+		Signup{Role: "user", Password: "secret-pass", Confirm: "secret-pass", Login: "john", Phone: "+79001234567"},
+		Signup{Role: "admin", Password: "secret", Confirm: "secret-pass", Login: "secret", Team: true},
+		Signup{Role: "admin", Password: "secret-pass", Confirm: "secret-pass", Login: "john", Phone: "1"},
+		Booking{Start: start, End: &end, MinSeats: 1, MaxSeats: 1, Limit: Limit{Seats: 2}},
+		Booking{Start: start, Price: &price, MinSeats: -2, MaxSeats: -1},
+		Booking{
+			Start: start, End: &before, MinSeats: 3, MaxSeats: 2, Limit: Limit{Seats: 2},
+			Price: &price, Deposit: &deposit,
+		},
+		*root,
+		Node{},
+	}
+}
+
+func TestGeneratedConformance(t *testing.T) {
+	for i, v := range conformanceValues() {
+		t.Run(fmt.Sprintf("%d %T", i, v), func(t *testing.T) {
+			v := v
+			t.Parallel()
+
+			requireSameErrors(t, Validate(v), v.Validate())
+		})
+	}
+}
+
+func TestGeneratedAllocations(t *testing.T) {
+	user := validUser
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := user.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// the path is preallocated, nothing else is allocated for valid values
+	if allocs > 1 {
+		t.Fatalf("expected at most 1 allocation, got %v", allocs)
+	}
+}
+
+func requireSameErrors(t *testing.T, expected, actual error) {
+	t.Helper()
+
+	if expected == nil || actual == nil {
+		if expected != actual { //nolint:errorlint
+			t.Fatalf("expected %v, got %v", expected, actual)
+		}
+		return
+	}
+
+	var expectedErrs, actualErrs ValidationErrors
+	if !errors.As(expected, &expectedErrs) || !errors.As(actual, &actualErrs) {
+		t.Fatalf("expected ValidationErrors, got %v and %v", expected, actual)
+	}
+
+	if expected.Error() != actual.Error() || len(expectedErrs) != len(actualErrs) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+
+	for i, e := range expectedErrs {
+		a := actualErrs[i]
+		if e.Field != a.Field || e.Rule != a.Rule || e.Param != a.Param || e.Err.Error() != a.Err.Error() {
+			t.Errorf("error %d: expected %+v, got %+v", i, e, a)
+		}
+
+		for _, sentinel := range sentinels {
+			if errors.Is(e.Err, sentinel) != errors.Is(a.Err, sentinel) {
+				t.Errorf("error %d: errors.Is(%v, %v) differs", i, a.Err, sentinel)
+			}
+		}
+	}
+}